@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"go.uber.org/multierr"
+)
+
+const (
+	checkFrontCoverType     = "front-cover-type"
+	checkMissingFolderCover = "missing-folder-cover"
+
+	defaultCoverMinDimension    = 500
+	defaultCoverMaxDimension    = 3000
+	defaultCoverSquareTolerance = 0.05
+)
+
+var (
+	defaultCoverMIMETypes = []string{"image/jpeg", "image/png"}
+
+	// coverIssueSeverities maps the cover quality issues to the severity of their findings
+	coverIssueSeverities = map[string]internal.Severity{
+		pkg.CoverTooSmall:     internal.SeverityWarning,
+		pkg.CoverTooLarge:     internal.SeverityInfo,
+		pkg.CoverNotSquare:    internal.SeverityInfo,
+		pkg.CoverTooManyBytes: internal.SeverityInfo,
+		pkg.CoverMIMEType:     internal.SeverityWarning,
+	}
+)
+
+// checkCoverQuality checks the embedded pictures and the folder cover of each album against the
+// requirements. Resolution and squareness are only checked for front covers, size and MIME type for
+// all embedded pictures. Files with pictures but without a front cover and albums without a folder
+// cover are reported as well.
+func checkCoverQuality(collectedMetadata map[string]map[string]string, requirements pkg.CoverRequirements) ([]internal.Finding, error) {
+	var findings []internal.Finding
+	addIssues := func(subject, prefix string, issues []pkg.CoverIssue) {
+		for _, issue := range issues {
+			findings = append(findings, internal.Finding{
+				Rule:     issue.Issue,
+				Severity: coverIssueSeverities[issue.Issue],
+				Subject:  subject,
+				Message:  prefix + issue.Message,
+			})
+		}
+	}
+
+	pictureRequirements := pkg.CoverRequirements{MaxBytes: requirements.MaxBytes, MIMETypes: requirements.MIMETypes}
+
+	var errs error
+	for dir, files := range groupFilesByDir(collectedMetadata) {
+		for _, file := range files {
+			images, err := internal.GetFlacImages(file)
+			if err != nil {
+				errs = multierr.Append(errs, err)
+				continue
+			}
+
+			hasFront := false
+			for _, image := range images {
+				prefix := fmt.Sprintf("picture %d (%s): ", image.BlockNumber, image.Type)
+				if image.TypeID() != internal.PictureTypeFront {
+					addIssues(file, prefix, pictureRequirements.Check(0, 0, image.Bytes(), image.MIMEType))
+					continue
+				}
+
+				hasFront = true
+				width, height := image.Dimensions()
+				addIssues(file, prefix, requirements.Check(width, height, image.Bytes(), image.MIMEType))
+			}
+
+			if len(images) > 0 && !hasFront {
+				findings = append(findings, internal.Finding{
+					Rule:     checkFrontCoverType,
+					Severity: internal.SeverityWarning,
+					Subject:  file,
+					Message:  fmt.Sprintf("none of the %d pictures is a front cover", len(images)),
+				})
+			}
+		}
+
+		folderCover, hasFolderCover, err := fetchFolderCover(dir)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if !hasFolderCover {
+			findings = append(findings, internal.Finding{
+				Rule:     checkMissingFolderCover,
+				Severity: internal.SeverityWarning,
+				Subject:  dir,
+				Message:  "no cover image",
+			})
+			continue
+		}
+
+		info, err := os.Stat(folderCover.Path)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		_, _, mimeType, err := pkg.IsValidImage(folderCover.Path)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		addIssues(folderCover.Path, "folder cover: ", requirements.Check(folderCover.Width, folderCover.Height, info.Size(), mimeType))
+	}
+
+	internal.SortFindings(findings)
+	return findings, errs
+}
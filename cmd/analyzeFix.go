@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"go.uber.org/multierr"
+)
+
+const (
+	fixRemoveTags   = "remove-tags"
+	fixFillTags     = "fill-tags"
+	fixResolveTags  = "resolve-tags"
+	fixTrackNumbers = "track-numbers"
+	fixEmbedCover   = "embed-cover"
+)
+
+// fixOrder is the order in which the fixes of a file are applied
+var fixOrder = []string{fixRemoveTags, fixFillTags, fixResolveTags, fixTrackNumbers, fixEmbedCover}
+
+// remediation is a single fix for a single file
+type remediation struct {
+	File       string
+	Fix        string
+	SetTags    map[string]string `json:",omitempty"`
+	RemoveTags []string          `json:",omitempty"`
+	Cover      string            `json:",omitempty"`
+}
+
+// Change describes the remediation in a human-friendly way
+func (r remediation) Change() string {
+	var changes []string
+	for _, tag := range r.RemoveTags {
+		changes = append(changes, "-"+tag)
+	}
+
+	tags := make([]string, 0, len(r.SetTags))
+	for tag := range r.SetTags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		changes = append(changes, fmt.Sprintf("%s=%s", tag, r.SetTags[tag]))
+	}
+
+	if r.Cover != "" {
+		changes = append(changes, filepath.Base(r.Cover))
+	}
+
+	return strings.Join(changes, ", ")
+}
+
+// planRemediation attaches fixes to the findings of analyze: undesired tags are removed, missing uniform
+// tags are filled with the album's majority value, resolved values are written, track numbers are
+// normalized and the folder cover is embedded into files without any picture.
+func planRemediation(result analyzeResult, collectedMetadata map[string]map[string]string) ([]remediation, error) {
+	var plan []remediation
+
+	for file, tags := range result.UndesiredTags {
+		fix := remediation{File: file, Fix: fixRemoveTags}
+		for tag := range tags {
+			fix.RemoveTags = append(fix.RemoveTags, tag)
+		}
+		sort.Strings(fix.RemoveTags)
+		plan = append(plan, fix)
+	}
+
+	for file, tags := range fillMissingTags(result.MissingTags, collectedMetadata) {
+		plan = append(plan, remediation{File: file, Fix: fixFillTags, SetTags: tags})
+	}
+
+	for file, tags := range result.TagFixes {
+		plan = append(plan, remediation{File: file, Fix: fixResolveTags, SetTags: tags})
+	}
+
+	var errs error
+	for file, metadata := range collectedMetadata {
+		if _, found := metadata[internal.TagTrackNumber]; !found {
+			continue
+		}
+
+		// the collected metadata has zero-padded track numbers already
+		values, err := internal.FetchTagValues(file, internal.TagTrackNumber)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		if len(values) != 1 {
+			continue
+		}
+
+		if tags := trackNumberFixes(values[0], metadata[internal.TagTracksTotal]); len(tags) > 0 {
+			plan = append(plan, remediation{File: file, Fix: fixTrackNumbers, SetTags: tags})
+		}
+	}
+
+	folderCovers := make(map[string]string)
+	for _, file := range result.MissingCovers {
+		dir := filepath.Dir(file)
+		cover, found := folderCovers[dir]
+		if !found {
+			info, hasCover, err := fetchFolderCover(dir)
+			if err != nil {
+				errs = multierr.Append(errs, err)
+			}
+			if hasCover {
+				cover = info.Path
+			}
+			folderCovers[dir] = cover
+		}
+
+		if cover != "" {
+			plan = append(plan, remediation{File: file, Fix: fixEmbedCover, Cover: cover})
+		}
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		if plan[i].File != plan[j].File {
+			return plan[i].File < plan[j].File
+		}
+		return slices.Index(fixOrder, plan[i].Fix) < slices.Index(fixOrder, plan[j].Fix)
+	})
+
+	return plan, errs
+}
+
+// fillMissingTags returns the majority value of the album for each missing tag of a file. Tags without
+// a majority are left alone.
+// returns file - { tag: value }
+func fillMissingTags(missingTags map[string][]string, collectedMetadata map[string]map[string]string) map[string]map[string]string {
+	var tags []string
+	for _, missing := range missingTags {
+		for _, tag := range missing {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	dirValues := collectTagValues(collectedMetadata, tags)
+
+	fills := make(map[string]map[string]string)
+	for file, missing := range missingTags {
+		for _, tag := range missing {
+			values := dirValues[filepath.Dir(file)][tag]
+			if len(values) == 0 {
+				continue
+			}
+
+			value, err := internal.ResolveMajority(values)
+			if err != nil || value == "" {
+				continue
+			}
+
+			if _, found := fills[file]; !found {
+				fills[file] = make(map[string]string)
+			}
+			fills[file][tag] = value
+		}
+	}
+	return fills
+}
+
+// trackNumberFixes returns the tags needed to normalize the raw TRACKNUMBER value of a file, e.g. "3/12"
+// becomes TRACKNUMBER=03 and TRACKTOTAL=12 unless the file has a TRACKTOTAL already.
+func trackNumberFixes(value, tracksTotal string) map[string]string {
+	number, total, ok := internal.NormalizeTrackNumber(value)
+	if !ok {
+		return nil
+	}
+
+	fixes := make(map[string]string)
+	if number != value {
+		fixes[internal.TagTrackNumber] = number
+	}
+	if total != "" && tracksTotal == "" {
+		fixes[internal.TagTracksTotal] = total
+	}
+	return fixes
+}
+
+// applyRemediation presents the plan and applies it after confirmation
+func applyRemediation(plan []remediation) error {
+	if len(plan) == 0 {
+		tui.Info("Nothing to fix")
+		return nil
+	}
+
+	var data [][]string
+	for _, fix := range plan {
+		data = append(data, []string{fix.File, fix.Fix, fix.Change()})
+	}
+	tui.PrintTable("Fixes", []string{"File", "Fix", "Change"}, data, tui.TableOpts{})
+
+	proceed, err := tui.Confirm("Proceed with applying fixes?")
+	if err != nil {
+		return err
+	}
+
+	if !proceed {
+		return nil
+	}
+
+	var errs error
+	for _, fix := range plan {
+		if err := applyFix(fix); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s %s: %w", fix.Fix, fix.File, err))
+		}
+	}
+
+	return errs
+}
+
+func applyFix(fix remediation) error {
+	if len(fix.RemoveTags) > 0 {
+		tags := make(map[string]string, len(fix.RemoveTags))
+		for _, tag := range fix.RemoveTags {
+			tags[tag] = ""
+		}
+		if err := internal.RemoveMetadata(fix.File, tags); err != nil {
+			return err
+		}
+	}
+
+	if len(fix.SetTags) > 0 {
+		if err := internal.SetMetadata(fix.File, fix.SetTags, true); err != nil {
+			return err
+		}
+	}
+
+	if fix.Cover != "" {
+		return internal.AddPicture(fix.File, fix.Cover, internal.PictureTypeFront, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/soerenschneider/flac-mate/internal"
+)
+
+func TestTrackNumberFixes(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		tracksTotal string
+		want        map[string]string
+	}{
+		{name: "bare number", value: "3", want: map[string]string{internal.TagTrackNumber: "03"}},
+		{name: "padded", value: "03"},
+		{name: "with total", value: "3/12", want: map[string]string{internal.TagTrackNumber: "03", internal.TagTracksTotal: "12"}},
+		{name: "with existing total", value: "3/12", tracksTotal: "12", want: map[string]string{internal.TagTrackNumber: "03"}},
+		{name: "spaces", value: " 3 ", want: map[string]string{internal.TagTrackNumber: "03"}},
+		{name: "three digits", value: "7/120", want: map[string]string{internal.TagTrackNumber: "007", internal.TagTracksTotal: "120"}},
+		{name: "not a number", value: "A1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := trackNumberFixes(tt.value, tt.tracksTotal)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trackNumberFixes(%q, %q) = %v, want %v", tt.value, tt.tracksTotal, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"sort"
+
+	"github.com/soerenschneider/flac-mate/internal"
+	"go.uber.org/multierr"
+)
+
+// Names of the naming checks
+const (
+	checkFileName          = "file-name"
+	checkDirectoryName     = "directory-name"
+	checkNameUndetermined  = "name-undetermined"
+	checkNamingStreamInfos = "naming-stream-info"
+)
+
+// checkNaming reports files and album directories whose names differ from the names rename would
+// produce with the given unwrapped schemes. Nothing is renamed. Resolved album tags take precedence
+// over the values of the individual tracks, just like they do for rename.
+// resolvedTags is dir - { tag: value }
+func checkNaming(collectedMetadata map[string]map[string]string, resolvedTags map[string]map[string]string, fileScheme, dirScheme string) []internal.Finding {
+	needsStreamInfo := usesTechnicalTags(fileScheme) || usesTechnicalTags(dirScheme)
+
+	albums := make(map[string][]string)
+	for file := range collectedMetadata {
+		dir := filepath.Dir(file)
+		albums[dir] = append(albums[dir], file)
+	}
+
+	var findings []internal.Finding
+	for dir, files := range albums {
+		sort.Strings(files)
+
+		// work on copies, the synthetic technical tags must not leak into the other checks
+		metadata := make(map[string]map[string]string, len(files))
+		for _, file := range files {
+			metadata[file] = maps.Clone(collectedMetadata[file])
+		}
+
+		var albumTechnicalMetadata map[string]string
+		if needsStreamInfo {
+			var err error
+			albumTechnicalMetadata, err = addTechnicalMetadata(files, metadata)
+			if err != nil {
+				findings = append(findings, internal.Finding{
+					Rule:     checkNamingStreamInfos,
+					Severity: internal.SeverityInfo,
+					Subject:  dir,
+					Message:  err.Error(),
+				})
+			}
+		}
+
+		albumMetadata := make(map[string]map[string]bool)
+		for _, file := range files {
+			if !hasSufficientMetadata(metadata[file], fileScheme) {
+				findings = append(findings, internal.Finding{
+					Rule:     checkNameUndetermined,
+					Severity: internal.SeverityInfo,
+					Subject:  file,
+					Message:  fmt.Sprintf("missing tags for file scheme, requires %v", schemeTags(fileScheme)),
+				})
+				continue
+			}
+
+			if _, expected, differs := renameFile(fileScheme, dir, filepath.Base(file), metadata[file]); differs {
+				findings = append(findings, internal.Finding{
+					Rule:     checkFileName,
+					Severity: internal.SeverityWarning,
+					Subject:  file,
+					Message:  fmt.Sprintf("expected %q", filepath.Base(expected)),
+				})
+			}
+			appendMetadata(albumMetadata, metadata[file])
+		}
+
+		for tag, value := range resolvedTags[dir] {
+			albumMetadata[tag] = map[string]bool{value: true}
+		}
+
+		// technical properties of the album replace the ones of the individual tracks
+		if needsStreamInfo {
+			for _, tag := range internal.SyntheticMappings {
+				albumMetadata[tag] = map[string]bool{}
+				if value, found := albumTechnicalMetadata[tag]; found {
+					albumMetadata[tag][value] = true
+				}
+			}
+		}
+
+		if _, err := canRenameDirectory(albumMetadata, dirScheme); err != nil {
+			findings = append(findings, internal.Finding{
+				Rule:     checkNameUndetermined,
+				Severity: internal.SeverityInfo,
+				Subject:  dir,
+				Message:  fmt.Sprintf("cannot determine directory name: %v", err),
+			})
+			continue
+		}
+
+		singleMetadata := make(map[string]string)
+		for tag, values := range albumMetadata {
+			if len(values) == 1 {
+				for value := range values {
+					singleMetadata[tag] = value
+				}
+			}
+		}
+
+		// a relative album dir such as "." has no name of its own to compare
+		albumDir, err := filepath.Abs(dir)
+		if err != nil {
+			albumDir = dir
+		}
+
+		if _, expected, differs := renameDir(dirScheme, albumDir, singleMetadata); differs {
+			findings = append(findings, internal.Finding{
+				Rule:     checkDirectoryName,
+				Severity: internal.SeverityWarning,
+				Subject:  dir,
+				Message:  fmt.Sprintf("expected %q", filepath.Base(expected)),
+			})
+		}
+	}
+
+	internal.SortFindings(findings)
+	return findings
+}
+
+// addTechnicalMetadata adds the synthetic technical tags to the metadata of the files and returns the
+// technical metadata of the album
+func addTechnicalMetadata(files []string, metadata map[string]map[string]string) (map[string]string, error) {
+	var errs error
+	var streamInfos []internal.StreamInfo
+	for _, file := range files {
+		streamInfo, err := internal.FetchStreamInfo(file)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		streamInfos = append(streamInfos, streamInfo)
+		maps.Copy(metadata[file], internal.TrackTechnicalMetadata(streamInfo))
+	}
+
+	if len(streamInfos) == 0 {
+		return nil, errs
+	}
+
+	albumTechnicalMetadata, err := internal.AlbumTechnicalMetadata(streamInfos)
+	if err != nil {
+		errs = multierr.Append(errs, err)
+	}
+
+	// the album size is the same for every track
+	if size, found := albumTechnicalMetadata[internal.SyntheticAlbumSizeTag]; found {
+		for _, file := range files {
+			metadata[file][internal.SyntheticAlbumSizeTag] = size
+		}
+	}
+
+	return albumTechnicalMetadata, errs
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/soerenschneider/flac-mate/internal"
+)
+
+func TestCheckNaming(t *testing.T) {
+	fileScheme, err := unwrapKeys("%n - %t", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirScheme, err := unwrapKeys("%a - %b", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := func(track, title string) map[string]string {
+		metadata := map[string]string{
+			internal.TagArtist:      "Artist",
+			internal.TagAlbum:       "Album",
+			internal.TagTrackNumber: track,
+		}
+		if title != "" {
+			metadata[internal.TagTitle] = title
+		}
+		return metadata
+	}
+
+	tests := []struct {
+		name string
+		// files are relative to a temporary directory, or to the album directory with inAlbumDir
+		files      map[string]map[string]string
+		inAlbumDir bool
+		want       []string
+	}{
+		{
+			name:  "matching names",
+			files: map[string]map[string]string{"Artist - Album/01 - One.flac": tags("01", "One")},
+		},
+		{
+			name:  "differing names",
+			files: map[string]map[string]string{"Album/1 - One.flac": tags("01", "One")},
+			want:  []string{checkDirectoryName, checkFileName},
+		},
+		{
+			name:  "missing tags",
+			files: map[string]map[string]string{"Artist - Album/01.flac": tags("01", "")},
+			want:  []string{checkNameUndetermined, checkNameUndetermined},
+		},
+		{
+			name:       "current directory",
+			files:      map[string]map[string]string{"01 - One.flac": tags("01", "One")},
+			inAlbumDir: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.inAlbumDir {
+				dir = filepath.Join(dir, "Artist - Album")
+				if err := os.Mkdir(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				t.Chdir(dir)
+			}
+
+			collectedMetadata := make(map[string]map[string]string)
+			for name, metadata := range tt.files {
+				if !tt.inAlbumDir {
+					name = filepath.Join(dir, name)
+				}
+				collectedMetadata[name] = metadata
+			}
+
+			var got []string
+			for _, finding := range checkNaming(collectedMetadata, nil, fileScheme, dirScheme) {
+				got = append(got, finding.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkNaming() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
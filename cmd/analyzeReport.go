@@ -0,0 +1,425 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soerenschneider/flac-mate/internal"
+)
+
+const (
+	reportFormatTable = "table"
+	reportFormatJson  = "json"
+	reportFormatJUnit = "junit"
+	reportFormatSarif = "sarif"
+	reportFormatHtml  = "html"
+
+	// failOnNever disables failing on findings
+	failOnNever = "never"
+)
+
+var reportFormats = []string{reportFormatTable, reportFormatJson, reportFormatJUnit, reportFormatSarif, reportFormatHtml}
+
+// Names and severities of the built-in checks of analyze
+const (
+	checkMissingCover        = "missing-cover"
+	checkMissingTag          = "missing-tag"
+	checkMultiValuedTag      = "multi-valued-tag"
+	checkUndesiredTag        = "undesired-tag"
+	checkMixedCovers         = "mixed-covers"
+	checkLowResolutionCover  = "low-resolution-cover"
+	checkFolderCoverMismatch = "folder-cover-mismatch"
+	checkMissingFrontCover   = "missing-front-cover"
+	checkVisualCoverOutlier  = "visual-cover-outlier"
+	checkSharedCover         = "shared-cover"
+	checkUnreadableCover     = "unreadable-cover"
+)
+
+// AllFindings returns the results of the built-in checks and the rule findings as a single list
+// ordered by severity (highest first), check and subject.
+func (ar *analyzeResult) AllFindings() []internal.Finding {
+	var findings []internal.Finding
+	add := func(check string, severity internal.Severity, subject, tag, message string) {
+		findings = append(findings, internal.Finding{Rule: check, Severity: severity, Subject: subject, Tag: tag, Message: message})
+	}
+
+	for _, file := range ar.MissingCovers {
+		add(checkMissingCover, internal.SeverityWarning, file, "", "no embedded picture")
+	}
+	for file, tags := range ar.MissingTags {
+		for _, tag := range tags {
+			add(checkMissingTag, internal.SeverityWarning, file, tag, "missing")
+		}
+	}
+	for dir, tags := range ar.MultiValuedTags {
+		for tag, values := range tags {
+			add(checkMultiValuedTag, internal.SeverityError, dir, tag, fmt.Sprintf("multiple values %q", values))
+		}
+	}
+	for file, tags := range ar.UndesiredTags {
+		for tag, value := range tags {
+			add(checkUndesiredTag, internal.SeverityInfo, file, tag, fmt.Sprintf("undesired tag with value %q", value))
+		}
+	}
+
+	if ar.Covers != nil {
+		for dir, count := range ar.Covers.MixedCovers {
+			add(checkMixedCovers, internal.SeverityWarning, dir, "", fmt.Sprintf("%d distinct embedded front covers", count))
+		}
+		for file, resolution := range ar.Covers.LowResolutionCovers {
+			add(checkLowResolutionCover, internal.SeverityInfo, file, "", resolution)
+		}
+		for file, cover := range ar.Covers.FolderCoverMismatches {
+			add(checkFolderCoverMismatch, internal.SeverityWarning, file, "", fmt.Sprintf("differs from %s", cover))
+		}
+		for _, file := range ar.Covers.MissingFrontCovers {
+			add(checkMissingFrontCover, internal.SeverityWarning, file, "", "no front cover")
+		}
+		for file, distance := range ar.Covers.VisualOutliers {
+			add(checkVisualCoverOutlier, internal.SeverityWarning, file, "", fmt.Sprintf("looks different from the album's cover (distance %d)", distance))
+		}
+		for _, shared := range ar.Covers.SharedCovers {
+			add(checkSharedCover, internal.SeverityWarning, shared.Dir, "", fmt.Sprintf("cover of %q looks like the cover of %q (%s)", shared.Album, shared.OtherAlbum, shared.OtherDir))
+		}
+		for dir, errs := range ar.Covers.UnreadableCovers {
+			for _, err := range errs {
+				add(checkUnreadableCover, internal.SeverityWarning, dir, "", err)
+			}
+		}
+	}
+
+	findings = append(findings, ar.Numbering...)
+	findings = append(findings, ar.Hygiene...)
+	findings = append(findings, ar.Technical...)
+	findings = append(findings, ar.Naming...)
+	findings = append(findings, ar.CoverQuality...)
+	findings = append(findings, ar.Genres...)
+	findings = append(findings, ar.Findings...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity.Rank() != b.Severity.Rank() {
+			return a.Severity.Rank() > b.Severity.Rank()
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		return a.Tag < b.Tag
+	})
+
+	return findings
+}
+
+// countFailing returns the number of findings at or above the severity to fail on
+func countFailing(findings []internal.Finding, failOn string) int {
+	if failOn == failOnNever {
+		return 0
+	}
+
+	threshold := internal.Severity(failOn).Rank()
+	count := 0
+	for _, finding := range findings {
+		if finding.Severity.Rank() >= threshold {
+			count++
+		}
+	}
+	return count
+}
+
+func validateFailOn(failOn string) error {
+	if failOn == failOnNever {
+		return nil
+	}
+	if _, err := internal.ParseSeverity(failOn); err != nil {
+		return fmt.Errorf("%w or %q", err, failOnNever)
+	}
+	return nil
+}
+
+// groupFindingsByRule returns the names of the rules in order of appearance and their findings
+func groupFindingsByRule(findings []internal.Finding) ([]string, map[string][]internal.Finding) {
+	var rules []string
+	grouped := make(map[string][]internal.Finding)
+	for _, finding := range findings {
+		if _, found := grouped[finding.Rule]; !found {
+			rules = append(rules, finding.Rule)
+		}
+		grouped[finding.Rule] = append(grouped[finding.Rule], finding)
+	}
+	return rules, grouped
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes a test suite per rule with a failed test case per finding
+func writeJUnitReport(w io.Writer, findings []internal.Finding) error {
+	report := junitTestSuites{Name: "flac-mate analyze"}
+	timestamp := time.Now().Format(time.RFC3339)
+
+	rules, grouped := groupFindingsByRule(findings)
+	for _, rule := range rules {
+		suite := junitTestSuite{Name: rule, Timestamp: timestamp}
+		for _, finding := range grouped[rule] {
+			name := finding.Subject
+			if finding.Tag != "" {
+				name = fmt.Sprintf("%s [%s]", finding.Subject, finding.Tag)
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      name,
+				ClassName: rule,
+				Failure: &junitFailure{
+					Message: finding.Message,
+					Type:    string(finding.Severity),
+					Text:    fmt.Sprintf("%s: %s", finding.Subject, finding.Message),
+				},
+			})
+		}
+		suite.Tests = len(suite.Cases)
+		suite.Failures = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type sarifReport struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevels maps severities to SARIF result levels
+var sarifLevels = map[internal.Severity]string{
+	internal.SeverityInfo:    "note",
+	internal.SeverityWarning: "warning",
+	internal.SeverityError:   "error",
+}
+
+// writeSarifReport writes the findings in the structure of SARIF 2.1.0
+func writeSarifReport(w io.Writer, findings []internal.Finding) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:    "flac-mate",
+			Version: internal.BuildVersion,
+			Rules:   make([]sarifRule, 0),
+		}},
+		Results: make([]sarifResult, 0, len(findings)),
+	}
+
+	rules, _ := groupFindingsByRule(findings)
+	for _, rule := range rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: rule})
+	}
+
+	for _, finding := range findings {
+		message := finding.Message
+		if finding.Tag != "" {
+			message = fmt.Sprintf("%s: %s", finding.Tag, finding.Message)
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  finding.Rule,
+			Level:   sarifLevels[finding.Severity],
+			Message: sarifMessage{Text: message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: finding.Subject}},
+			}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifReport{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>flac-mate analyze: {{.Target}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+.error { color: #b00020; }
+.warning { color: #b26a00; }
+.info { color: #555; }
+</style>
+</head>
+<body>
+<h1>flac-mate analyze</h1>
+<p>{{.Target}}, {{.Generated}}</p>
+<ul>
+{{- range .Counts}}
+<li class="{{.Severity}}">{{.Count}} {{.Severity}}</li>
+{{- end}}
+</ul>
+{{- if not .Rules}}
+<p>No findings.</p>
+{{- end}}
+{{- range .Rules}}
+<h2 class="{{.Severity}}">{{.Name}} ({{len .Findings}})</h2>
+<table>
+<tr><th>Severity</th><th>Subject</th><th>Tag</th><th>Finding</th></tr>
+{{- range .Findings}}
+<tr><td class="{{.Severity}}">{{.Severity}}</td><td>{{.Subject}}</td><td>{{.Tag}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeHtmlReport writes a standalone HTML page with a table per rule
+func writeHtmlReport(w io.Writer, target string, findings []internal.Finding) error {
+	type severityCount struct {
+		Severity internal.Severity
+		Count    int
+	}
+	type ruleFindings struct {
+		Name     string
+		Severity internal.Severity
+		Findings []internal.Finding
+	}
+
+	data := struct {
+		Target    string
+		Generated string
+		Counts    []severityCount
+		Rules     []ruleFindings
+	}{
+		Target:    target,
+		Generated: time.Now().Format(time.RFC1123),
+	}
+
+	for i := len(internal.Severities) - 1; i >= 0; i-- {
+		severity := internal.Severities[i]
+		count := 0
+		for _, finding := range findings {
+			if finding.Severity == severity {
+				count++
+			}
+		}
+		data.Counts = append(data.Counts, severityCount{Severity: severity, Count: count})
+	}
+
+	rules, grouped := groupFindingsByRule(findings)
+	for _, rule := range rules {
+		data.Rules = append(data.Rules, ruleFindings{Name: rule, Severity: grouped[rule][0].Severity, Findings: grouped[rule]})
+	}
+
+	return htmlReportTemplate.Execute(w, data)
+}
+
+// writeReport writes the findings in a machine-readable format
+func writeReport(w io.Writer, format string, result analyzeResult) error {
+	switch strings.ToLower(format) {
+	case reportFormatJson:
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(encoded))
+		return err
+	case reportFormatJUnit:
+		return writeJUnitReport(w, result.AllFindings())
+	case reportFormatSarif:
+		return writeSarifReport(w, result.AllFindings())
+	case reportFormatHtml:
+		return writeHtmlReport(w, result.Target, result.AllFindings())
+	}
+
+	return fmt.Errorf("unknown report format %q, valid formats are %v", format, reportFormats)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/soerenschneider/flac-mate/internal"
+)
+
+var testFindings = []internal.Finding{
+	{Rule: "missing-tag", Severity: internal.SeverityWarning, Subject: "/a/1.flac", Tag: internal.TagDate, Message: "missing"},
+	{Rule: "missing-tag", Severity: internal.SeverityWarning, Subject: "/a/2.flac", Tag: internal.TagDate, Message: "missing"},
+	{Rule: "undesired-tag", Severity: internal.SeverityInfo, Subject: "/a/1.flac", Tag: "ENCODER", Message: `undesired tag with value "x"`},
+	{Rule: "multi-valued-tag", Severity: internal.SeverityError, Subject: "/a", Tag: internal.TagAlbum, Message: "multiple values"},
+}
+
+func TestCountFailing(t *testing.T) {
+	tests := map[string]int{
+		string(internal.SeverityInfo):    4,
+		string(internal.SeverityWarning): 3,
+		string(internal.SeverityError):   1,
+		failOnNever:                      0,
+	}
+	for failOn, want := range tests {
+		if got := countFailing(testFindings, failOn); got != want {
+			t.Errorf("countFailing(%q) = %d, want %d", failOn, got, want)
+		}
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	tests := map[string]bool{
+		"info":      false,
+		"warning":   false,
+		"ERROR":     false,
+		failOnNever: false,
+		"critical":  true,
+		"":          true,
+	}
+	for failOn, wantErr := range tests {
+		if err := validateFailOn(failOn); (err != nil) != wantErr {
+			t.Errorf("validateFailOn(%q) error = %v, wantErr %v", failOn, err, wantErr)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "no error", want: ExitCodeClean},
+		{name: "findings", err: findingsError(3, "warning"), want: ExitCodeFindings},
+		{name: "analysis failed", err: &ExitError{Code: ExitCodeError, Err: errors.New("failed")}, want: ExitCodeError},
+		{name: "other error", err: errors.New("failed"), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUsageErrors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown flag":     {"metadata", "analyze", "--bogus", t.TempDir()},
+		"missing argument": {"metadata", "analyze"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			RootCmd.SetArgs(args)
+			RootCmd.SetOut(io.Discard)
+			RootCmd.SetErr(io.Discard)
+			defer RootCmd.SetArgs(nil)
+
+			if got := ExitCode(RootCmd.Execute()); got != ExitCodeError {
+				t.Errorf("ExitCode() = %d, want %d", got, ExitCodeError)
+			}
+		})
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJUnitReport(&buf, testFindings); err != nil {
+		t.Fatalf("writeJUnitReport() error = %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+
+	if report.Tests != 4 || report.Failures != 4 {
+		t.Errorf("tests = %d, failures = %d, want 4, 4", report.Tests, report.Failures)
+	}
+
+	var suites []string
+	for _, suite := range report.Suites {
+		suites = append(suites, suite.Name)
+		if suite.Tests != len(suite.Cases) || suite.Failures != len(suite.Cases) {
+			t.Errorf("suite %q counts %d tests and %d failures for %d cases", suite.Name, suite.Tests, suite.Failures, len(suite.Cases))
+		}
+	}
+	if want := []string{"missing-tag", "undesired-tag", "multi-valued-tag"}; !reflect.DeepEqual(suites, want) {
+		t.Errorf("suites = %v, want %v", suites, want)
+	}
+
+	failure := report.Suites[0].Cases[0].Failure
+	if report.Suites[0].Cases[0].Name != "/a/1.flac [DATE]" || failure == nil || failure.Type != "warning" {
+		t.Errorf("unexpected test case %+v", report.Suites[0].Cases[0])
+	}
+}
+
+func TestWriteSarifReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSarifReport(&buf, testFindings); err != nil {
+		t.Fatalf("writeSarifReport() error = %v", err)
+	}
+
+	var report sarifReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+
+	if report.Version != "2.1.0" || len(report.Runs) != 1 {
+		t.Fatalf("version = %q, runs = %d, want 2.1.0 and 1 run", report.Version, len(report.Runs))
+	}
+
+	run := report.Runs[0]
+	if want := []sarifRule{{ID: "missing-tag"}, {ID: "undesired-tag"}, {ID: "multi-valued-tag"}}; !reflect.DeepEqual(run.Tool.Driver.Rules, want) {
+		t.Errorf("rules = %v, want %v", run.Tool.Driver.Rules, want)
+	}
+
+	var levels []string
+	for _, result := range run.Results {
+		levels = append(levels, result.Level)
+	}
+	if want := []string{"warning", "warning", "note", "error"}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("levels = %v, want %v", levels, want)
+	}
+
+	result := run.Results[0]
+	if result.Message.Text != "DATE: missing" || result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "/a/1.flac" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestWriteSarifReportWithoutFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSarifReport(&buf, nil); err != nil {
+		t.Fatalf("writeSarifReport() error = %v", err)
+	}
+
+	var report map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+
+	results := report["runs"].([]any)[0].(map[string]any)["results"]
+	if results == nil {
+		t.Errorf("results = null, want an empty list")
+	}
+}
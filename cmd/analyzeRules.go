@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+)
+
+const rulesFileName = "rules.yaml"
+
+// configFile returns the path of a file in the user's config directory
+func configFile(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "flac-mate", name), nil
+}
+
+// defaultRulesFile returns the path of the rules file in the user's config directory
+func defaultRulesFile() (string, error) {
+	return configFile(rulesFileName)
+}
+
+// loadAnalyzeRules loads the rules from the given file or, if no file is given, from the default rules
+// file if it exists. Returns nil if there are no rules to evaluate.
+func loadAnalyzeRules(path string) (*internal.RuleSet, error) {
+	if path != "" {
+		return internal.LoadRuleSet(pkg.GetExpandedFile(path))
+	}
+
+	path, err := defaultRulesFile()
+	if err != nil {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return internal.LoadRuleSet(path)
+}
+
+// printFindings prints a table per rule or check, the findings are expected to be ordered by rule
+func printFindings(kind string, findings []internal.Finding) {
+	for start := 0; start < len(findings); {
+		end := start
+		var data [][]string
+		for ; end < len(findings) && findings[end].Rule == findings[start].Rule; end++ {
+			data = append(data, []string{findings[end].Subject, findings[end].Tag, findings[end].Message})
+		}
+
+		tui.PrintTable(
+			fmt.Sprintf("%s %s (%s)", kind, findings[start].Rule, findings[start].Severity),
+			[]string{"Subject", "Tag", "Finding"},
+			data,
+			tui.TableOpts{},
+		)
+		start = end
+	}
+}
+
+// findingsSummary returns a summary line per rule or check, the findings are expected to be ordered by rule
+func findingsSummary(kind string, findings []internal.Finding, numberStyle, categoryStyle, detailStyle lipgloss.Style) []string {
+	var lines []string
+	for start := 0; start < len(findings); {
+		end := start
+		for end < len(findings) && findings[end].Rule == findings[start].Rule {
+			end++
+		}
+
+		count := numberStyle.Render(fmt.Sprintf("%d", end-start))
+		category := categoryStyle.Render(fmt.Sprintf("violations of %s %s", kind, findings[start].Rule))
+		detail := detailStyle.Render(fmt.Sprintf("(%s)", findings[start].Severity))
+		lines = append(lines, fmt.Sprintf("%s %s %s", count, category, detail))
+		start = end
+	}
+	return lines
+}
@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// covers of different albums within this perceptual distance are considered to be the same picture
+	maxSharedCoverDistance = 4
+	// embedded covers further away from the album's prevailing cover are considered to be different pictures
+	minCoverOutlierDistance = 12
+)
+
+// coverInfo describes a cover image by its content hash and dimensions
+type coverInfo struct {
+	Path   string
+	Hash   string
+	Width  int
+	Height int
+	// PerceptualHash is only computed on request, see pkg.DifferenceHash
+	PerceptualHash uint64
+}
+
+func (c coverInfo) Pixels() int {
+	return c.Width * c.Height
+}
+
+func (c coverInfo) Resolution() string {
+	return fmt.Sprintf("%dx%d", c.Width, c.Height)
+}
+
+// fetchEmbeddedFrontCover returns the first embedded front cover of a flac, optionally including its
+// perceptual hash. The bool is false if the file has no front cover.
+func fetchEmbeddedFrontCover(file string, perceptual bool) (coverInfo, bool, error) {
+	images, err := internal.GetFlacImages(file)
+	if err != nil {
+		return coverInfo{}, false, err
+	}
+
+	filter := internal.PictureFilter{Types: []int{internal.PictureTypeFront}}
+	fronts := filter.Filter(images)
+	if len(fronts) == 0 {
+		return coverInfo{}, false, nil
+	}
+
+	data, err := internal.ReadPicture(file, fronts[0].BlockNumber)
+	if err != nil {
+		return coverInfo{}, false, err
+	}
+
+	width, height := fronts[0].Dimensions()
+	cover := coverInfo{
+		Path:   file,
+		Hash:   dataHash(data),
+		Width:  width,
+		Height: height,
+	}
+
+	if perceptual {
+		cover.PerceptualHash, err = pkg.DifferenceHashData(data)
+		if err != nil {
+			return coverInfo{}, false, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return cover, true, nil
+}
+
+// fetchFolderCover returns the main cover image of a directory. The bool is false if the directory
+// has no images or none of them is confidently the front cover, e.g. if there is only a back cover.
+func fetchFolderCover(dir string) (coverInfo, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return coverInfo{}, false, err
+	}
+
+	var images []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isImage(filepath.Join(dir, entry.Name())) {
+			images = append(images, entry.Name())
+		}
+	}
+	sort.Strings(images)
+
+	if len(images) == 0 {
+		return coverInfo{}, false, nil
+	}
+
+	cover := pkg.ScoreCovers(dir, images)[0]
+	if !pkg.IsConfidentCover(cover) {
+		return coverInfo{}, false, nil
+	}
+
+	path := filepath.Join(dir, cover.Name)
+	hash, err := folderCoverHash(path)
+	if err != nil {
+		return coverInfo{}, false, err
+	}
+
+	width, height, err := pkg.ImageDimensions(path)
+	if err != nil {
+		return coverInfo{}, false, err
+	}
+
+	return coverInfo{
+		Path:   path,
+		Hash:   hash,
+		Width:  width,
+		Height: height,
+	}, true, nil
+}
+
+// chooseFolderCover picks the main cover among the images of a directory. If interactive is set and the
+// best scored image is not a confident choice, the candidates are offered in a chooser. With allowNone,
+// the chooser offers to pick none of the images. An empty name is returned if no image has been chosen.
+func chooseFolderCover(dir string, images []string, interactive, allowNone bool) (string, error) {
+	if len(images) == 0 {
+		return "", nil
+	}
+
+	candidates := pkg.ScoreCovers(dir, images)
+	if !interactive || !pkg.IsAmbiguousCoverChoice(candidates) {
+		return candidates[0].Name, nil
+	}
+
+	labels := make([]string, 0, len(candidates)+1)
+	for _, candidate := range candidates {
+		labels = append(labels, fmt.Sprintf("%s (%dx%d, %s, score %.2f)", candidate.Name, candidate.Width, candidate.Height, pkg.HumanSize(candidate.Size), candidate.Score))
+	}
+	if allowNone {
+		labels = append(labels, "None of these")
+	}
+
+	if flagPreview {
+		for i, candidate := range candidates {
+			fmt.Println(labels[i])
+			if err := printImagePreview(filepath.Join(dir, candidate.Name)); err != nil {
+				tui.Warn(err.Error())
+			}
+		}
+	}
+
+	choice, err := tui.SelectOption(fmt.Sprintf("Which image is the cover of %q?", dir), labels)
+	if err != nil {
+		return "", err
+	}
+
+	if choice == len(candidates) {
+		return "", nil
+	}
+	return candidates[choice].Name, nil
+}
+
+// addPreviewFlags registers the flags to enable inline previews of pictures on a command
+func addPreviewFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&flagPreview, "preview", false, "Preview pictures inline")
+	cmd.Flags().StringVar(&flagPreviewProtocol, "preview-protocol", string(tui.PreviewAuto), fmt.Sprintf("Protocol of the previews %v, implies --preview if set", tui.PreviewProtocols))
+	cmd.Flags().IntVar(&flagPreviewColumns, "preview-width", tui.DefaultPreviewColumns, "Width of previews in terminal columns")
+}
+
+// validatePreviewFlags enables previews if a protocol is given and makes sure the protocol is known
+func validatePreviewFlags(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("preview-protocol") {
+		flagPreview = true
+	}
+	if !flagPreview {
+		return nil
+	}
+	_, err := tui.ParsePreviewProtocol(flagPreviewProtocol)
+	return err
+}
+
+// printImagePreview renders the image file inline according to the preview flags
+func printImagePreview(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return printPreview(data)
+}
+
+// printPreview renders the image data inline according to the preview flags
+func printPreview(data []byte) error {
+	protocol, err := tui.ParsePreviewProtocol(flagPreviewProtocol)
+	if err != nil {
+		return err
+	}
+	return tui.PrintPreview(data, protocol, flagPreviewColumns)
+}
+
+// folderCoverHash returns the hash of the cover as it would be embedded. Covers that are neither JPEG nor
+// PNG get converted when embedding, so the hash of the converted image is returned for them.
+func folderCoverHash(path string) (string, error) {
+	_, format, _, err := pkg.IsValidImage(path)
+	if err != nil {
+		return "", err
+	}
+
+	if pkg.IsEmbeddableFormat(format) {
+		return fileHash(path)
+	}
+
+	data, _, err := pkg.ConvertImage(path)
+	if err != nil {
+		return "", err
+	}
+	return dataHash(data), nil
+}
+
+func dataHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// groupFilesByDir groups files by their directory, files are sorted within each directory
+func groupFilesByDir[T any](files map[string]T) map[string][]string {
+	grouped := make(map[string][]string)
+	for file := range files {
+		dir := filepath.Dir(file)
+		grouped[dir] = append(grouped[dir], file)
+	}
+	for _, dirFiles := range grouped {
+		sort.Strings(dirFiles)
+	}
+	return grouped
+}
+
+// coverConsistency holds the findings of comparing the embedded front covers of an album's tracks
+// with each other and with the folder cover
+type coverConsistency struct {
+	// dir - number of distinct embedded front covers
+	MixedCovers map[string]int
+	// file - description
+	LowResolutionCovers map[string]string
+	// file - folder cover
+	FolderCoverMismatches map[string]string
+	MissingFrontCovers    []string
+	// file - perceptual distance to the prevailing cover of its album
+	VisualOutliers map[string]int `json:",omitempty"`
+	SharedCovers   []sharedCover  `json:",omitempty"`
+	// dir - errors reading the folder cover or embedded covers of the album
+	UnreadableCovers map[string][]string `json:",omitempty"`
+}
+
+// sharedCover describes two albums whose covers look nearly identical
+type sharedCover struct {
+	Dir        string
+	Album      string
+	OtherDir   string
+	OtherAlbum string
+	Distance   int
+}
+
+// albumIdentity returns "ALBUMARTIST - ALBUM" of the first of the files, falling back to ARTIST
+func albumIdentity(files []string, collectedMetadata map[string]map[string]string) string {
+	metadata := collectedMetadata[files[0]]
+	artist := metadata[internal.TagAlbumArtist]
+	if artist == "" {
+		artist = metadata[internal.TagArtist]
+	}
+	return fmt.Sprintf("%s - %s", artist, metadata[internal.TagAlbum])
+}
+
+// prevailingHash returns the perceptual hash with the smallest total distance to all other hashes
+func prevailingHash(hashes []uint64) uint64 {
+	best, bestTotal := hashes[0], -1
+	for _, candidate := range hashes {
+		total := 0
+		for _, hash := range hashes {
+			total += pkg.HammingDistance(candidate, hash)
+		}
+		if bestTotal < 0 || total < bestTotal {
+			best, bestTotal = candidate, total
+		}
+	}
+	return best
+}
+
+// checkCoverConsistency compares the embedded front covers. With perceptual, the covers are compared
+// visually as well, within each album and across albums. Covers that cannot be read are reported per album.
+func checkCoverConsistency(collectedMetadata map[string]map[string]string, perceptual bool) coverConsistency {
+	result := coverConsistency{
+		MixedCovers:           make(map[string]int),
+		LowResolutionCovers:   make(map[string]string),
+		FolderCoverMismatches: make(map[string]string),
+		MissingFrontCovers:    make([]string, 0),
+		UnreadableCovers:      make(map[string][]string),
+	}
+	if perceptual {
+		result.VisualOutliers = make(map[string]int)
+		result.SharedCovers = make([]sharedCover, 0)
+	}
+
+	// dir - prevailing perceptual hash of the album
+	albumHashes := make(map[string]uint64)
+
+	for dir, files := range groupFilesByDir(collectedMetadata) {
+		folderCover, hasFolderCover, err := fetchFolderCover(dir)
+		if err != nil {
+			result.UnreadableCovers[dir] = append(result.UnreadableCovers[dir], err.Error())
+		}
+
+		hashes := make(map[string]bool)
+		perceptualHashes := make(map[string]uint64)
+		for _, file := range files {
+			embedded, found, err := fetchEmbeddedFrontCover(file, perceptual)
+			if err != nil {
+				result.UnreadableCovers[dir] = append(result.UnreadableCovers[dir], fmt.Sprintf("%s: %v", filepath.Base(file), err))
+				continue
+			}
+
+			if !found {
+				result.MissingFrontCovers = append(result.MissingFrontCovers, file)
+				continue
+			}
+			hashes[embedded.Hash] = true
+			if perceptual {
+				perceptualHashes[file] = embedded.PerceptualHash
+			}
+
+			if !hasFolderCover || embedded.Hash == folderCover.Hash {
+				continue
+			}
+
+			if embedded.Pixels() < folderCover.Pixels() {
+				result.LowResolutionCovers[file] = fmt.Sprintf("%s < %s (%s)", embedded.Resolution(), folderCover.Resolution(), filepath.Base(folderCover.Path))
+			} else {
+				result.FolderCoverMismatches[file] = folderCover.Path
+			}
+		}
+
+		if len(hashes) > 1 {
+			result.MixedCovers[dir] = len(hashes)
+		}
+
+		if len(perceptualHashes) > 0 {
+			var albumFiles []string
+			var all []uint64
+			for _, file := range files {
+				if hash, found := perceptualHashes[file]; found {
+					albumFiles = append(albumFiles, file)
+					all = append(all, hash)
+				}
+			}
+
+			prevailing := prevailingHash(all)
+			albumHashes[dir] = prevailing
+			for i, file := range albumFiles {
+				if distance := pkg.HammingDistance(prevailing, all[i]); distance >= minCoverOutlierDistance {
+					result.VisualOutliers[file] = distance
+				}
+			}
+		}
+	}
+
+	if perceptual {
+		result.SharedCovers = findSharedCovers(albumHashes, collectedMetadata)
+	}
+
+	return result
+}
+
+// findSharedCovers returns the pairs of albums with a different identity whose covers look nearly identical
+func findSharedCovers(albumHashes map[string]uint64, collectedMetadata map[string]map[string]string) []sharedCover {
+	filesByDir := groupFilesByDir(collectedMetadata)
+
+	dirs := make([]string, 0, len(albumHashes))
+	for dir := range albumHashes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	identities := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		identities[dir] = albumIdentity(filesByDir[dir], collectedMetadata)
+	}
+
+	shared := make([]sharedCover, 0)
+	for i, dir := range dirs {
+		for _, other := range dirs[i+1:] {
+			// e.g. the discs of an album in separate directories
+			if strings.EqualFold(identities[dir], identities[other]) {
+				continue
+			}
+
+			if distance := pkg.HammingDistance(albumHashes[dir], albumHashes[other]); distance <= maxSharedCoverDistance {
+				shared = append(shared, sharedCover{
+					Dir:        dir,
+					Album:      identities[dir],
+					OtherDir:   other,
+					OtherAlbum: identities[other],
+					Distance:   distance,
+				})
+			}
+		}
+	}
+
+	return shared
+}
+
+func (c coverConsistency) IsEmpty() bool {
+	return len(c.MixedCovers) == 0 && len(c.LowResolutionCovers) == 0 && len(c.FolderCoverMismatches) == 0 && len(c.MissingFrontCovers) == 0 &&
+		len(c.VisualOutliers) == 0 && len(c.SharedCovers) == 0 && len(c.UnreadableCovers) == 0
+}
@@ -0,0 +1,465 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+var discsCmd = &cobra.Command{
+	Use: "discs",
+	Aliases: []string{
+		"disc",
+	},
+	Short: "Flatten or split disc subfolders of multi-disc albums",
+}
+
+var discsFlattenCmd = &cobra.Command{
+	Use:   "flatten [album]",
+	Short: "Move the tracks of disc subfolders (e.g. CD1, CD2) into the album folder",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDiscsFlatten,
+}
+
+var discsSplitCmd = &cobra.Command{
+	Use:   "split [album]",
+	Short: "Move the tracks of a multi-disc album folder into disc subfolders",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDiscsSplit,
+}
+
+var (
+	// matches disc folders such as "CD1", "Disc 2" or "disk_03"
+	discDirRegex = regexp.MustCompile(`(?i)^(?:cd|disc|disk)[\s_-]*(\d+)$`)
+	// matches disc-prefixed track files such as "1-01 - Title.flac"
+	discFileRegex = regexp.MustCompile(`^(\d+)-(\d+)`)
+	// matches per-disc artwork such as "cd1.jpg" or "disc 2 front.png"
+	discImageRegex = regexp.MustCompile(`(?i)^(?:cd|disc|disk)[\s_-]*(\d+)`)
+
+	flagDiscsDryrun    bool
+	flagDiscsDirPrefix string
+)
+
+func init() {
+	RootCmd.AddCommand(discsCmd)
+	discsCmd.AddCommand(discsFlattenCmd)
+	discsCmd.AddCommand(discsSplitCmd)
+
+	discsCmd.PersistentFlags().BoolVarP(&flagDiscsDryrun, "dry-run", "n", false, "Dry run mode")
+	discsSplitCmd.Flags().StringVarP(&flagDiscsDirPrefix, "dir-prefix", "p", "CD", "Prefix of the disc subfolders, followed by the disc number")
+}
+
+type discsPlan struct {
+	CreateDirs []string
+	TagActions map[string]map[string]string
+	Moves      []renameMove
+	RemoveDirs []string
+}
+
+func newDiscsPlan() discsPlan {
+	return discsPlan{
+		TagActions: make(map[string]map[string]string),
+	}
+}
+
+func runDiscsFlatten(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := strings.TrimSuffix(pkg.GetExpandedFile(args[0]), "/")
+	action, err := flattenDiscs(target)
+	if err != nil {
+		return err
+	}
+
+	return action.Run()
+}
+
+func runDiscsSplit(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := strings.TrimSuffix(pkg.GetExpandedFile(args[0]), "/")
+	action, err := splitDiscs(target, flagDiscsDirPrefix)
+	if err != nil {
+		return err
+	}
+
+	return action.Run()
+}
+
+// flattenDiscs plans moving the files of all disc subfolders of an album into the album folder.
+// Tracks are prefixed with their disc number and get DISCNUMBER and DISCTOTAL written.
+func flattenDiscs(albumDir string) (*internal.GenericResult[discsPlan], error) {
+	entries, err := os.ReadDir(albumDir)
+	if err != nil {
+		return nil, err
+	}
+
+	discDirs := make(map[int]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		match := discDirRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		disc, _ := strconv.Atoi(match[1])
+		if existing, found := discDirs[disc]; found {
+			return nil, fmt.Errorf("found multiple folders for disc %d: %q and %q", disc, existing, entry.Name())
+		}
+		discDirs[disc] = entry.Name()
+	}
+
+	if len(discDirs) == 0 {
+		return nil, fmt.Errorf("no disc subfolders found in %q", albumDir)
+	}
+
+	plan := newDiscsPlan()
+	discTotal := strconv.Itoa(highestDisc(discDirs))
+	for _, disc := range sortedDiscs(discDirs) {
+		discDir := filepath.Join(albumDir, discDirs[disc])
+		discEntries, err := os.ReadDir(discDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range discEntries {
+			if entry.IsDir() {
+				return nil, fmt.Errorf("refusing to flatten %q, it contains the subfolder %q", discDir, entry.Name())
+			}
+
+			oldPath := filepath.Join(discDir, entry.Name())
+			switch {
+			case strings.HasSuffix(strings.ToLower(entry.Name()), ".flac"):
+				newName := entry.Name()
+				if match := discFileRegex.FindStringSubmatch(newName); match == nil {
+					newName = fmt.Sprintf("%d-%s", disc, newName)
+				}
+				plan.Moves = append(plan.Moves, renameMove{Kind: renameKindFile, Old: oldPath, New: filepath.Join(albumDir, newName)})
+				plan.TagActions[oldPath] = map[string]string{
+					internal.TagDiscNumber: strconv.Itoa(disc),
+					internal.TagDiscsTotal: discTotal,
+				}
+			case isImage(oldPath):
+				newName := fmt.Sprintf("disc%d-%s", disc, entry.Name())
+				plan.Moves = append(plan.Moves, renameMove{Kind: renameKindArtwork, Old: oldPath, New: filepath.Join(albumDir, newName)})
+			default:
+				newName := fmt.Sprintf("disc%d-%s", disc, entry.Name())
+				plan.Moves = append(plan.Moves, renameMove{Kind: renameKindFile, Old: oldPath, New: filepath.Join(albumDir, newName)})
+			}
+		}
+
+		plan.RemoveDirs = append(plan.RemoveDirs, discDir)
+	}
+
+	if err := checkMoveTargets(plan.Moves); err != nil {
+		return nil, err
+	}
+
+	return &internal.GenericResult[discsPlan]{
+		Operation: "discs-flatten",
+		Data:      plan,
+		Execute:   discsAction,
+	}, nil
+}
+
+// splitDiscs plans moving the tracks of a flat multi-disc album into disc subfolders. The disc of a track
+// is taken from its DISCNUMBER tag or, if missing, from a disc prefix of its filename such as "2-01".
+func splitDiscs(albumDir string, dirPrefix string) (*internal.GenericResult[discsPlan], error) {
+	entries, err := os.ReadDir(albumDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make(map[string]int)
+	var images []string
+	var errs error
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(albumDir, entry.Name())
+		if !strings.HasSuffix(strings.ToLower(entry.Name()), ".flac") {
+			if isImage(path) {
+				images = append(images, entry.Name())
+			}
+			continue
+		}
+
+		disc, err := discOfTrack(path)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		tracks[entry.Name()] = disc
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	plan, err := planSplitDiscs(albumDir, dirPrefix, tracks, images)
+	if err != nil {
+		return nil, err
+	}
+
+	return &internal.GenericResult[discsPlan]{
+		Operation: "discs-split",
+		Data:      plan,
+		Execute:   discsAction,
+	}, nil
+}
+
+// planSplitDiscs plans the disc subfolders and the moves of the tracks and the per-disc artwork.
+// tracks is filename - disc
+func planSplitDiscs(albumDir string, dirPrefix string, tracks map[string]int, images []string) (discsPlan, error) {
+	discs := make(map[int]string)
+	for _, disc := range tracks {
+		discs[disc] = filepath.Join(albumDir, fmt.Sprintf("%s%d", dirPrefix, disc))
+	}
+
+	if len(discs) < 2 {
+		return discsPlan{}, fmt.Errorf("%q does not contain tracks of multiple discs", albumDir)
+	}
+
+	plan := newDiscsPlan()
+	discTotal := strconv.Itoa(highestDisc(discs))
+	for _, disc := range sortedDiscs(discs) {
+		if _, err := os.Stat(discs[disc]); err == nil {
+			return discsPlan{}, fmt.Errorf("disc folder %q already exists", discs[disc])
+		}
+		plan.CreateDirs = append(plan.CreateDirs, discs[disc])
+	}
+
+	names := make([]string, 0, len(tracks))
+	for name := range tracks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		disc := tracks[name]
+		oldPath := filepath.Join(albumDir, name)
+		newName := name
+		if match := discFileRegex.FindStringSubmatch(name); match != nil {
+			newName = strings.TrimLeft(strings.TrimPrefix(name, match[1]+"-"), " ")
+		}
+
+		plan.Moves = append(plan.Moves, renameMove{Kind: renameKindFile, Old: oldPath, New: filepath.Join(discs[disc], newName)})
+		plan.TagActions[oldPath] = map[string]string{
+			internal.TagDiscNumber: strconv.Itoa(disc),
+			internal.TagDiscsTotal: discTotal,
+		}
+	}
+
+	// artwork that belongs to a single disc is moved alongside its tracks
+	for _, image := range images {
+		match := discImageRegex.FindStringSubmatch(image)
+		if match == nil {
+			continue
+		}
+		disc, _ := strconv.Atoi(match[1])
+		discDir, found := discs[disc]
+		if !found {
+			continue
+		}
+		plan.Moves = append(plan.Moves, renameMove{Kind: renameKindArtwork, Old: filepath.Join(albumDir, image), New: filepath.Join(discDir, image)})
+	}
+
+	if err := checkMoveTargets(plan.Moves); err != nil {
+		return discsPlan{}, err
+	}
+
+	return plan, nil
+}
+
+// discOfTrack returns the disc number of a track from its DISCNUMBER tag or its filename.
+func discOfTrack(path string) (int, error) {
+	metadata, err := internal.FetchMetadata(path, []string{internal.TagDiscNumber}, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if disc, found := parseDisc(metadata[internal.TagDiscNumber], filepath.Base(path)); found {
+		return disc, nil
+	}
+
+	return 0, fmt.Errorf("could not determine disc of %q", path)
+}
+
+// parseDisc returns the disc number from a DISCNUMBER value, which may be written as "1/2", or from a disc
+// prefix of the filename such as "2-01".
+func parseDisc(discNumber, filename string) (int, bool) {
+	if discNumber != "" {
+		disc, err := strconv.Atoi(strings.TrimSpace(strings.Split(discNumber, "/")[0]))
+		if err == nil && disc > 0 {
+			return disc, true
+		}
+	}
+
+	if match := discFileRegex.FindStringSubmatch(filename); match != nil {
+		disc, _ := strconv.Atoi(match[1])
+		if disc > 0 {
+			return disc, true
+		}
+	}
+
+	return 0, false
+}
+
+// highestDisc returns the highest disc number, it is the disc total even if discs in between are missing
+func highestDisc[T any](discs map[int]T) int {
+	keys := sortedDiscs(discs)
+	return keys[len(keys)-1]
+}
+
+func sortedDiscs[T any](discs map[int]T) []int {
+	keys := make([]int, 0, len(discs))
+	for disc := range discs {
+		keys = append(keys, disc)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// checkMoveTargets makes sure that no move overwrites another move's target or an existing file that is not
+// moved away by another move.
+func checkMoveTargets(moves []renameMove) error {
+	sources := make(map[string]bool, len(moves))
+	for _, move := range moves {
+		sources[move.Old] = true
+	}
+
+	targets := make(map[string]bool)
+	var errs error
+	for _, move := range moves {
+		if targets[move.New] {
+			errs = multierr.Append(errs, fmt.Errorf("multiple files would be moved to %q", move.New))
+		}
+		targets[move.New] = true
+
+		if _, err := os.Stat(move.New); err == nil && !sources[move.New] {
+			errs = multierr.Append(errs, fmt.Errorf("refusing to overwrite %q", move.New))
+		}
+	}
+	return errs
+}
+
+// moveFiles carries out the moves in order. A move whose target is the source of a later move, e.g. when
+// two files swap their names, is staged through a temporary name until the target has been moved away.
+func moveFiles(moves []renameMove) error {
+	pending := make(map[string]bool, len(moves))
+	for _, move := range moves {
+		pending[move.Old] = true
+	}
+
+	// target - temporary path of the moves waiting for their target to be moved away
+	staged := make(map[string]string)
+	for _, move := range moves {
+		// artwork may be moved to a subfolder that does not exist yet
+		if move.Kind == renameKindArtwork {
+			if err := os.MkdirAll(filepath.Dir(move.New), 0755); err != nil {
+				return err
+			}
+		}
+
+		target := move.New
+		if pending[move.New] {
+			target = filepath.Join(filepath.Dir(move.New), fmt.Sprintf(".%s.flac-mate", filepath.Base(move.New)))
+			staged[move.New] = target
+		}
+
+		if err := os.Rename(move.Old, target); err != nil {
+			return err
+		}
+		delete(pending, move.Old)
+
+		if temporary, found := staged[move.Old]; found {
+			if err := os.Rename(temporary, move.Old); err != nil {
+				return err
+			}
+			delete(staged, move.Old)
+		}
+	}
+
+	return nil
+}
+
+func discsAction(action *internal.GenericResult[discsPlan]) error {
+	plan := action.Data
+	if len(plan.Moves) == 0 {
+		successStyle := lipgloss.NewStyle().Bold(true)
+		fmt.Println(successStyle.Render("✓ Nothing to do!"))
+		return nil
+	}
+
+	if len(plan.TagActions) > 0 {
+		var data [][]string
+		for file, tags := range plan.TagActions {
+			for tag, value := range tags {
+				data = append(data, []string{file, tag, value})
+			}
+		}
+		sort.Slice(data, func(i, j int) bool {
+			return data[i][0]+data[i][1] < data[j][0]+data[j][1]
+		})
+		tui.PrintTable("Write Tags", []string{"File", "Tag", "Value"}, data, tui.TableOpts{})
+	}
+
+	var data [][]string
+	for _, move := range plan.Moves {
+		data = append(data, []string{move.Kind, move.Old, move.New})
+	}
+	tui.PrintTable("Move", []string{"Type", "Old", "New"}, data, tui.TableOpts{})
+
+	if flagDiscsDryrun {
+		return nil
+	}
+
+	proceed, err := tui.Confirm("Proceed?")
+	if err != nil {
+		return err
+	}
+
+	if !proceed {
+		return nil
+	}
+
+	// tags need to be written before the files are moved away
+	for file, tags := range plan.TagActions {
+		if err := internal.SetMetadata(file, tags, false); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range plan.CreateDirs {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := moveFiles(plan.Moves); err != nil {
+		return err
+	}
+
+	for _, dir := range plan.RemoveDirs {
+		if err := os.Remove(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			tui.Warn(fmt.Sprintf("could not remove %q: %v", dir, err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/soerenschneider/flac-mate/internal"
+)
+
+func TestDiscRegexes(t *testing.T) {
+	tests := []struct {
+		name      string
+		regex     string
+		value     string
+		wantMatch bool
+		wantDisc  string
+	}{
+		{name: "cd", regex: "dir", value: "CD1", wantMatch: true, wantDisc: "1"},
+		{name: "disc with space", regex: "dir", value: "Disc 2", wantMatch: true, wantDisc: "2"},
+		{name: "disk with underscore", regex: "dir", value: "disk_03", wantMatch: true, wantDisc: "03"},
+		{name: "dir with suffix", regex: "dir", value: "CD1 Bonus", wantMatch: false},
+		{name: "dir without number", regex: "dir", value: "Discography", wantMatch: false},
+		{name: "file prefix", regex: "file", value: "2-01 - Title.flac", wantMatch: true, wantDisc: "2"},
+		{name: "file without prefix", regex: "file", value: "01 - Title.flac", wantMatch: false},
+		{name: "image", regex: "image", value: "cd1.jpg", wantMatch: true, wantDisc: "1"},
+		{name: "image with suffix", regex: "image", value: "disc 2 front.png", wantMatch: true, wantDisc: "2"},
+		{name: "image of album", regex: "image", value: "cover.jpg", wantMatch: false},
+	}
+
+	regexes := map[string]func(string) []string{
+		"dir":   discDirRegex.FindStringSubmatch,
+		"file":  discFileRegex.FindStringSubmatch,
+		"image": discImageRegex.FindStringSubmatch,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := regexes[tt.regex](tt.value)
+			if (match != nil) != tt.wantMatch {
+				t.Fatalf("match of %q = %v, want %v", tt.value, match, tt.wantMatch)
+			}
+			if match != nil && match[1] != tt.wantDisc {
+				t.Errorf("disc of %q = %q, want %q", tt.value, match[1], tt.wantDisc)
+			}
+		})
+	}
+}
+
+func TestParseDisc(t *testing.T) {
+	tests := []struct {
+		name       string
+		discNumber string
+		filename   string
+		want       int
+		wantFound  bool
+	}{
+		{name: "tag", discNumber: "2", filename: "01 - Title.flac", want: 2, wantFound: true},
+		{name: "tag with total", discNumber: "2/3", filename: "01 - Title.flac", want: 2, wantFound: true},
+		{name: "tag wins over filename", discNumber: "1", filename: "2-01 - Title.flac", want: 1, wantFound: true},
+		{name: "filename", filename: "2-01 - Title.flac", want: 2, wantFound: true},
+		{name: "invalid tag", discNumber: "A", filename: "3-01 - Title.flac", want: 3, wantFound: true},
+		{name: "disc zero", discNumber: "0", filename: "0-01 - Title.flac"},
+		{name: "unknown", filename: "01 - Title.flac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := parseDisc(tt.discNumber, tt.filename)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("parseDisc(%q, %q) = %d, %v, want %d, %v", tt.discNumber, tt.filename, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+// createFiles creates empty files, including their parent directories, below dir
+func createFiles(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFlattenDiscs(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		wantMoves []renameMove
+		wantTags  map[string]map[string]string
+		wantErr   bool
+	}{
+		{
+			name:  "flatten",
+			files: []string{"CD1/01 - One.flac", "CD1/notes.txt", "Disc 2/2-01 - Two.flac", "cover.jpg"},
+			wantMoves: []renameMove{
+				{Kind: renameKindFile, Old: "CD1/01 - One.flac", New: "1-01 - One.flac"},
+				{Kind: renameKindFile, Old: "CD1/notes.txt", New: "disc1-notes.txt"},
+				{Kind: renameKindFile, Old: "Disc 2/2-01 - Two.flac", New: "2-01 - Two.flac"},
+			},
+			wantTags: map[string]map[string]string{
+				"CD1/01 - One.flac":      {internal.TagDiscNumber: "1", internal.TagDiscsTotal: "2"},
+				"Disc 2/2-01 - Two.flac": {internal.TagDiscNumber: "2", internal.TagDiscsTotal: "2"},
+			},
+		},
+		{
+			name:  "missing disc",
+			files: []string{"CD1/01 - One.flac", "CD3/01 - Three.flac"},
+			wantMoves: []renameMove{
+				{Kind: renameKindFile, Old: "CD1/01 - One.flac", New: "1-01 - One.flac"},
+				{Kind: renameKindFile, Old: "CD3/01 - Three.flac", New: "3-01 - Three.flac"},
+			},
+			wantTags: map[string]map[string]string{
+				"CD1/01 - One.flac":   {internal.TagDiscNumber: "1", internal.TagDiscsTotal: "3"},
+				"CD3/01 - Three.flac": {internal.TagDiscNumber: "3", internal.TagDiscsTotal: "3"},
+			},
+		},
+		{
+			name:    "no disc folders",
+			files:   []string{"01 - One.flac"},
+			wantErr: true,
+		},
+		{
+			name:    "multiple folders of a disc",
+			files:   []string{"CD1/01 - One.flac", "Disc 1/01 - One.flac"},
+			wantErr: true,
+		},
+		{
+			name:    "nested folder",
+			files:   []string{"CD1/Scans/front.jpg"},
+			wantErr: true,
+		},
+		{
+			name:    "existing target",
+			files:   []string{"CD1/1-01 - One.flac", "1-01 - One.flac"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			createFiles(t, dir, tt.files...)
+
+			action, err := flattenDiscs(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("flattenDiscs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var wantMoves []renameMove
+			for _, move := range tt.wantMoves {
+				wantMoves = append(wantMoves, renameMove{Kind: move.Kind, Old: filepath.Join(dir, move.Old), New: filepath.Join(dir, move.New)})
+			}
+			if !reflect.DeepEqual(action.Data.Moves, wantMoves) {
+				t.Errorf("flattenDiscs() moves = %v, want %v", action.Data.Moves, wantMoves)
+			}
+
+			wantTags := make(map[string]map[string]string)
+			for file, tags := range tt.wantTags {
+				wantTags[filepath.Join(dir, file)] = tags
+			}
+			if !reflect.DeepEqual(action.Data.TagActions, wantTags) {
+				t.Errorf("flattenDiscs() tags = %v, want %v", action.Data.TagActions, wantTags)
+			}
+		})
+	}
+}
+
+func TestPlanSplitDiscs(t *testing.T) {
+	tests := []struct {
+		name      string
+		tracks    map[string]int
+		images    []string
+		existing  []string
+		wantDirs  []string
+		wantMoves []renameMove
+		wantTotal string
+		wantErr   bool
+	}{
+		{
+			name:     "split",
+			tracks:   map[string]int{"1-01 - One.flac": 1, "1-02 - Two.flac": 1, "03 - Three.flac": 2},
+			images:   []string{"cd2.jpg", "cover.jpg", "cd3.jpg"},
+			wantDirs: []string{"CD1", "CD2"},
+			wantMoves: []renameMove{
+				{Kind: renameKindFile, Old: "03 - Three.flac", New: "CD2/03 - Three.flac"},
+				{Kind: renameKindFile, Old: "1-01 - One.flac", New: "CD1/01 - One.flac"},
+				{Kind: renameKindFile, Old: "1-02 - Two.flac", New: "CD1/02 - Two.flac"},
+				{Kind: renameKindArtwork, Old: "cd2.jpg", New: "CD2/cd2.jpg"},
+			},
+			wantTotal: "2",
+		},
+		{
+			name:     "missing disc",
+			tracks:   map[string]int{"1-01 - One.flac": 1, "3-01 - Three.flac": 3},
+			wantDirs: []string{"CD1", "CD3"},
+			wantMoves: []renameMove{
+				{Kind: renameKindFile, Old: "1-01 - One.flac", New: "CD1/01 - One.flac"},
+				{Kind: renameKindFile, Old: "3-01 - Three.flac", New: "CD3/01 - Three.flac"},
+			},
+			wantTotal: "3",
+		},
+		{
+			name:    "single disc",
+			tracks:  map[string]int{"01 - One.flac": 1, "02 - Two.flac": 1},
+			wantErr: true,
+		},
+		{
+			name:     "existing disc folder",
+			tracks:   map[string]int{"1-01 - One.flac": 1, "2-01 - Two.flac": 2},
+			existing: []string{"CD2/notes.txt"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			createFiles(t, dir, tt.existing...)
+
+			plan, err := planSplitDiscs(dir, "CD", tt.tracks, tt.images)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("planSplitDiscs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var wantDirs []string
+			for _, name := range tt.wantDirs {
+				wantDirs = append(wantDirs, filepath.Join(dir, name))
+			}
+			if !reflect.DeepEqual(plan.CreateDirs, wantDirs) {
+				t.Errorf("planSplitDiscs() dirs = %v, want %v", plan.CreateDirs, wantDirs)
+			}
+
+			var wantMoves []renameMove
+			for _, move := range tt.wantMoves {
+				wantMoves = append(wantMoves, renameMove{Kind: move.Kind, Old: filepath.Join(dir, move.Old), New: filepath.Join(dir, move.New)})
+			}
+			if !reflect.DeepEqual(plan.Moves, wantMoves) {
+				t.Errorf("planSplitDiscs() moves = %v, want %v", plan.Moves, wantMoves)
+			}
+
+			for name, disc := range tt.tracks {
+				tags := plan.TagActions[filepath.Join(dir, name)]
+				if tags[internal.TagDiscNumber] != strconv.Itoa(disc) || tags[internal.TagDiscsTotal] != tt.wantTotal {
+					t.Errorf("planSplitDiscs() tags of %q = %v", name, tags)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMoveTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		moves   []renameMove
+		wantErr bool
+	}{
+		{name: "free target", files: []string{"a.jpg"}, moves: []renameMove{{Old: "a.jpg", New: "b.jpg"}}},
+		{name: "existing target", files: []string{"a.jpg", "b.jpg"}, moves: []renameMove{{Old: "a.jpg", New: "b.jpg"}}, wantErr: true},
+		{name: "swap", files: []string{"a.jpg", "b.jpg"}, moves: []renameMove{{Old: "a.jpg", New: "b.jpg"}, {Old: "b.jpg", New: "a.jpg"}}},
+		{name: "same target", files: []string{"a.jpg", "b.jpg"}, moves: []renameMove{{Old: "a.jpg", New: "c.jpg"}, {Old: "b.jpg", New: "c.jpg"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			createFiles(t, dir, tt.files...)
+
+			var moves []renameMove
+			for _, move := range tt.moves {
+				moves = append(moves, renameMove{Old: filepath.Join(dir, move.Old), New: filepath.Join(dir, move.New)})
+			}
+			if err := checkMoveTargets(moves); (err != nil) != tt.wantErr {
+				t.Errorf("checkMoveTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMoveFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []renameMove
+		// want is filename - content after the moves, the content is the original filename
+		want map[string]string
+	}{
+		{
+			name:  "swap",
+			moves: []renameMove{{Old: "cover.jpg", New: "front.jpg"}, {Old: "front.jpg", New: "cover.jpg"}},
+			want:  map[string]string{"cover.jpg": "front.jpg", "front.jpg": "cover.jpg"},
+		},
+		{
+			name:  "chain",
+			moves: []renameMove{{Old: "a.jpg", New: "b.jpg"}, {Old: "b.jpg", New: "c.jpg"}},
+			want:  map[string]string{"b.jpg": "a.jpg", "c.jpg": "b.jpg"},
+		},
+		{
+			name:  "artwork subfolder",
+			moves: []renameMove{{Kind: renameKindArtwork, Old: "a.jpg", New: "Artwork/back.jpg"}},
+			want:  map[string]string{"Artwork/back.jpg": "a.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			var moves []renameMove
+			for _, move := range tt.moves {
+				if err := os.WriteFile(filepath.Join(dir, move.Old), []byte(move.Old), 0644); err != nil {
+					t.Fatal(err)
+				}
+				moves = append(moves, renameMove{Kind: move.Kind, Old: filepath.Join(dir, move.Old), New: filepath.Join(dir, move.New)})
+			}
+
+			if err := moveFiles(moves); err != nil {
+				t.Fatalf("moveFiles() error = %v", err)
+			}
+
+			got := make(map[string]string)
+			err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return err
+				}
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(dir, path)
+				got[rel] = string(content)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moveFiles() files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
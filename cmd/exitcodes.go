@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of analyze, other commands exit with 1 on any error
+const (
+	ExitCodeClean    = 0
+	ExitCodeFindings = 1
+	ExitCodeError    = 2
+)
+
+// ExitError is returned by commands that need a specific exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the error returned by a command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeClean
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
+
+// usageError marks flag and argument errors of analyze with ExitCodeError, they must not be mistaken for
+// findings. It is used as the flag error function of the command.
+func usageError(_ *cobra.Command, err error) error {
+	return &ExitError{Code: ExitCodeError, Err: err}
+}
+
+// usageErrorArgs wraps an argument validator to return its errors as usage errors
+func usageErrorArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError(cmd, err)
+		}
+		return nil
+	}
+}
+
+// findingsError is returned by analyze if there are findings at or above the severity to fail on
+func findingsError(count int, failOn string) error {
+	return &ExitError{
+		Code: ExitCodeFindings,
+		Err:  fmt.Errorf("found %d findings with severity %s or higher", count, failOn),
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/pkg"
 	"github.com/spf13/cobra"
 )
 
@@ -15,12 +16,61 @@ var (
 		internal.TagTrackNumber,
 	}
 
-	flagMetaReadTags    []string
-	flagMetaWriteData   map[string]string
-	flagMetaUniformTags []string
-	flagMetaPictureFile string
-	flagMetaWriteForce  bool
-	flagMetaJsonOutput  bool
+	flagMetaReadTags            []string
+	flagMetaWriteData           map[string]string
+	flagMetaUniformTags         []string
+	flagMetaPictureFile         string
+	flagMetaPictureType         string
+	flagMetaPictureDescription  string
+	flagMetaPictureAddMode      string
+	flagMetaPictureAddPerAlbum  bool
+	flagMetaPictureOptimize     bool
+	flagMetaPictureMaxDimension int
+	flagMetaPictureQuality      int
+	flagMetaPictureMaxBytes     int64
+	flagMetaPictureExtractAll   bool
+	flagMetaPictureAllFiles     bool
+	flagMetaPictureOverwrite    bool
+	flagMetaPictureInteractive  bool
+	flagMetaPictureTypes        []string
+	flagMetaPictureIndexes      []int
+	flagMetaPictureMIMETypes    []string
+	flagMetaWriteForce          bool
+	flagMetaJsonOutput          bool
+	flagMetaNormalizeTags       []string
+
+	flagCaseStyle          string
+	flagCaseLanguage       string
+	flagCaseTags           []string
+	flagCaseExceptions     []string
+	flagCaseExceptionsFile string
+
+	flagGenresMap   string
+	flagGenresSplit bool
+
+	flagAnalyzeCovers     bool
+	flagAnalyzePerceptual bool
+	flagAnalyzeRules      string
+	flagAnalyzeGenres     string
+	flagAnalyzeGenreSplit bool
+	flagAnalyzeFix        bool
+	flagAnalyzeNaming     bool
+	flagAnalyzeFileScheme string
+	flagAnalyzeDirScheme  string
+	flagAnalyzeTechnical  bool
+
+	flagAnalyzeCoverQuality      bool
+	flagAnalyzeCoverRequirements pkg.CoverRequirements
+	flagAnalyzeFormat            string
+	flagAnalyzeOutput            string
+	flagAnalyzeFailOn            string
+
+	flagResolveStrategy  string
+	flagResolveWriteBack bool
+
+	flagPreview         bool
+	flagPreviewProtocol string
+	flagPreviewColumns  int
 )
 
 // CLI command structure
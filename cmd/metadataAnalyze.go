@@ -1,8 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -11,19 +12,88 @@ import (
 	"github.com/soerenschneider/flac-mate/internal"
 	"github.com/soerenschneider/flac-mate/internal/tui"
 	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
 )
 
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [target]",
 	Short: "Make sure that the supplied tags have only a single value across all files",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAnalyze,
+	Long: `Make sure that the supplied tags have only a single value across all files.
+
+The track numbers of each album directory are checked to form a complete sequence from 1 to TRACKTOTAL per
+disc: duplicate numbers, gaps, numbers exceeding TRACKTOTAL and missing tracks are reported, as well as
+titles occurring more than once within an album. Tag values are checked for surrounding whitespace, runs
+of spaces, decomposed characters, control characters and double-encoded UTF-8, see "metadata normalize".
+
+With --cover-quality, embedded pictures and folder covers are checked for their resolution, aspect ratio,
+size and MIME type. Files without a front cover picture and albums without a folder cover are reported too.
+
+With --technical, the stream properties of each album are audited: albums mixing bit depths and sample
+rates, mono and stereo tracks or encoder vendors are reported, as well as tracks without an audio MD5.
+
+With --naming, the names of files and album directories are compared to the names rename would produce
+with the given schemes. Nothing is renamed.
+
+Genres that are not spelled like a canonical genre are reported if there is a genre map, given by --genres
+or flac-mate/genres.yaml in the user's config directory, see "metadata genres".
+
+Additional rules are read from a YAML file given by --rules. Findings are grouped by rule. Rules apply to
+single tracks or, with scope album, to all tracks of a directory:
+
+  rules:
+    - name: classical-composer
+      severity: error          # info, warning (default) or error
+      when:
+        GENRE: Classical       # regular expressions the values have to match for the rule to apply
+      require: [COMPOSER]
+    - name: date-format
+      match:
+        DATE: '\d{4}(-\d{2}-\d{2})?'
+    - name: genres
+      severity: info
+      allowed:
+        GENRE: [Classical, Jazz, Rock]
+    - name: album-tags
+      scope: album
+      uniform: [ALBUM, ALBUMARTIST, DATE]
+      forbid: [COMMENT]
+
+Besides tables, the findings can be written as JSON, JUnit XML, SARIF or a standalone HTML page using
+--format and --output. The exit code is suitable for CI pipelines:
+
+  0  no findings at or above the --fail-on severity
+  1  findings at or above the --fail-on severity
+  2  the analysis itself failed or the flags or arguments are invalid`,
+	Args: usageErrorArgs(cobra.ExactArgs(1)),
+	RunE: runAnalyze,
 }
 
 func init() {
 	metadataCmd.AddCommand(analyzeCmd)
+	analyzeCmd.SetFlagErrorFunc(usageError)
 	analyzeCmd.Flags().StringSliceVarP(&flagMetaUniformTags, "tags", "t", defaultUniformCmdTags, "Tags to check for uniformity")
-	analyzeCmd.Flags().BoolVarP(&flagMetaJsonOutput, "json", "j", false, "Encode result to JSON instead of printing a human-friendly table")
+	analyzeCmd.Flags().BoolVarP(&flagMetaJsonOutput, "json", "j", false, "Encode result to JSON instead of printing a human-friendly table, same as --format json")
+	analyzeCmd.Flags().StringVarP(&flagAnalyzeFormat, "format", "F", reportFormatTable, fmt.Sprintf("Format of the report %v", reportFormats))
+	analyzeCmd.Flags().StringVarP(&flagAnalyzeOutput, "output", "o", "", "File to write the report to instead of stdout, not supported for tables")
+	analyzeCmd.Flags().StringVar(&flagAnalyzeFailOn, "fail-on", string(internal.SeverityWarning), fmt.Sprintf("Exit with code 1 on findings at or above this severity %v or %q to never fail on findings", internal.Severities, failOnNever))
+	analyzeCmd.Flags().BoolVarP(&flagAnalyzeCovers, "covers", "c", false, "Compare embedded front covers across tracks and against the folder cover")
+	analyzeCmd.Flags().BoolVarP(&flagAnalyzePerceptual, "perceptual", "p", false, "Compare embedded front covers visually within albums and across albums, implies --covers")
+	analyzeCmd.Flags().BoolVarP(&flagAnalyzeCoverQuality, "cover-quality", "q", false, "Check the quality of embedded and folder covers")
+	analyzeCmd.Flags().IntVar(&flagAnalyzeCoverRequirements.MinDimension, "cover-min-dimension", defaultCoverMinDimension, "Minimal width and height of covers in pixels, 0 to disable")
+	analyzeCmd.Flags().IntVar(&flagAnalyzeCoverRequirements.MaxDimension, "cover-max-dimension", defaultCoverMaxDimension, "Maximal width and height of covers in pixels, 0 to disable")
+	analyzeCmd.Flags().Int64Var(&flagAnalyzeCoverRequirements.MaxBytes, "cover-max-bytes", defaultMaxPictureBytes, "Maximal size of covers in bytes, 0 to disable")
+	analyzeCmd.Flags().StringSliceVar(&flagAnalyzeCoverRequirements.MIMETypes, "cover-mime", defaultCoverMIMETypes, "Allowed MIME types of covers, empty to allow all")
+	analyzeCmd.Flags().Float64Var(&flagAnalyzeCoverRequirements.SquareTolerance, "cover-square-tolerance", defaultCoverSquareTolerance, "Allowed deviation of the aspect ratio of covers from 1:1, 0 to disable")
+	analyzeCmd.Flags().BoolVarP(&flagAnalyzeTechnical, "technical", "T", false, "Check the stream properties and encoders of the tracks of each album for consistency")
+	analyzeCmd.Flags().BoolVar(&flagAnalyzeNaming, "naming", false, "Report files and directories whose names differ from the naming schemes")
+	analyzeCmd.Flags().StringVar(&flagAnalyzeFileScheme, "file-scheme", defaultRenameFileScheme, "File naming scheme to check against, implies --naming if set")
+	analyzeCmd.Flags().StringVar(&flagAnalyzeDirScheme, "directory-scheme", defaultRenameDirScheme, "Directory naming scheme to check against, implies --naming if set")
+	analyzeCmd.Flags().StringVarP(&flagAnalyzeGenres, "genres", "G", "", fmt.Sprintf("Genre map to check genres against, defaults to flac-mate/%s in the user's config directory if it exists", genresFileName))
+	analyzeCmd.Flags().BoolVar(&flagAnalyzeGenreSplit, "genres-split", false, "Suggest splitting combined genres into multiple GENRE values, see \"metadata genres --split\"")
+	analyzeCmd.Flags().StringVarP(&flagAnalyzeRules, "rules", "R", "", fmt.Sprintf("Rules file to evaluate, defaults to flac-mate/%s in the user's config directory if it exists", rulesFileName))
+	analyzeCmd.Flags().StringVarP(&flagResolveStrategy, "resolve", "r", resolveNone, fmt.Sprintf("Strategy to resolve multi-valued tags %v", resolveStrategies))
+	analyzeCmd.Flags().BoolVarP(&flagResolveWriteBack, "write-back", "w", false, "Write resolved values back to the files that differ, only with the table format")
+	analyzeCmd.Flags().BoolVar(&flagAnalyzeFix, "fix", false, "Plan fixes for the findings and apply them after confirmation, includes --write-back, only with the table format")
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
@@ -31,23 +101,77 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 
 	target := args[0]
 
-	action, err := analyzeMetadata(target)
+	if err := validateResolveStrategy(flagResolveStrategy); err != nil {
+		return &ExitError{Code: ExitCodeError, Err: err}
+	}
+
+	flagAnalyzeFailOn = strings.ToLower(strings.TrimSpace(flagAnalyzeFailOn))
+	if err := validateFailOn(flagAnalyzeFailOn); err != nil {
+		return &ExitError{Code: ExitCodeError, Err: err}
+	}
+
+	if flagMetaJsonOutput {
+		flagAnalyzeFormat = reportFormatJson
+	}
+	if !slices.Contains(reportFormats, flagAnalyzeFormat) {
+		return &ExitError{Code: ExitCodeError, Err: fmt.Errorf("unknown report format %q, valid formats are %v", flagAnalyzeFormat, reportFormats)}
+	}
+	if flagAnalyzeFormat == reportFormatTable && flagAnalyzeOutput != "" {
+		return &ExitError{Code: ExitCodeError, Err: errors.New("--output requires a --format other than table")}
+	}
+	// fixes and write-backs are confirmed interactively, which only works along with the table report
+	if flagAnalyzeFormat != reportFormatTable && (flagAnalyzeFix || flagResolveWriteBack) {
+		return &ExitError{Code: ExitCodeError, Err: errors.New("--fix and --write-back can only be used with the table format")}
+	}
+
+	ruleSet, err := loadAnalyzeRules(flagAnalyzeRules)
 	if err != nil {
-		return err
+		return &ExitError{Code: ExitCodeError, Err: err}
+	}
+
+	genreMap, err := loadGenreMap(flagAnalyzeGenres)
+	if err != nil {
+		return &ExitError{Code: ExitCodeError, Err: err}
+	}
+
+	for _, flag := range []string{"cover-min-dimension", "cover-max-dimension", "cover-max-bytes", "cover-mime", "cover-square-tolerance"} {
+		if cmd.Flags().Changed(flag) {
+			flagAnalyzeCoverQuality = true
+		}
+	}
+
+	if cmd.Flags().Changed("file-scheme") || cmd.Flags().Changed("directory-scheme") {
+		flagAnalyzeNaming = true
+	}
+
+	action, err := analyzeMetadata(target, ruleSet, genreMap)
+	if err != nil {
+		return &ExitError{Code: ExitCodeError, Err: err}
+	}
+
+	if err := action.Run(); err != nil {
+		return &ExitError{Code: ExitCodeError, Err: err}
 	}
 
-	return action.Run()
+	if count := countFailing(action.Data.AllFindings(), flagAnalyzeFailOn); count > 0 {
+		return findingsError(count, flagAnalyzeFailOn)
+	}
+
+	return nil
 }
 
-func analyzeMetadata(target string) (*internal.GenericResult[analyzeResult], error) {
+func analyzeMetadata(target string, ruleSet *internal.RuleSet, genreMap *internal.GenreMap) (*internal.GenericResult[analyzeResult], error) {
 	collectedMetadata, err := collectMetadataForFile(target)
 	if err != nil {
 		return nil, err
 	}
 
 	result := analyzeResult{
+		Target:        target,
 		MissingTags:   make(map[string][]string),
 		UndesiredTags: make(map[string]map[string]string),
+		ResolvedTags:  make(map[string]map[string]string),
+		TagFixes:      make(map[string]map[string]string),
 	}
 
 	wantedTags := map[string]bool{}
@@ -75,8 +199,30 @@ func analyzeMetadata(target string) (*internal.GenericResult[analyzeResult], err
 
 	result.MissingCovers = missingCovers
 
+	if flagAnalyzeCovers || flagAnalyzePerceptual {
+		covers := checkCoverConsistency(collectedMetadata, flagAnalyzePerceptual)
+		result.Covers = &covers
+	}
+
 	result.MultiValuedTags = getMultiValuedKeys(collectedMetadata, flagMetaUniformTags)
 
+	if flagResolveStrategy != resolveNone {
+		for dir, tagValues := range collectTagValues(collectedMetadata, flagMetaUniformTags) {
+			resolved, err := resolveTags(dir, tagValues, flagResolveStrategy)
+			if err != nil {
+				tui.Warn(err.Error())
+			}
+			if len(resolved) == 0 {
+				continue
+			}
+
+			result.ResolvedTags[dir] = resolved
+			for file, tags := range outlierFixes(tagValues, resolved) {
+				result.TagFixes[file] = tags
+			}
+		}
+	}
+
 	//
 	// Check for undesired tags
 	for file, metadata := range collectedMetadata {
@@ -91,6 +237,59 @@ func analyzeMetadata(target string) (*internal.GenericResult[analyzeResult], err
 		}
 	}
 
+	result.Numbering = internal.CheckTrackNumbering(collectedMetadata)
+	// the hygiene checks need the raw values, FetchMetadata trims them and keeps one value per tag
+	rawValues, err := collectRawTagValues(collectedMetadata)
+	if err != nil {
+		tui.Warn(err.Error())
+	}
+	result.Hygiene = internal.CheckTextHygiene(rawValues)
+
+	if flagAnalyzeTechnical {
+		// unreadable files are skipped, the other files are still checked
+		result.Technical, err = checkTechnicalConsistency(collectedMetadata)
+		if err != nil {
+			tui.Warn(err.Error())
+		}
+	}
+
+	if flagAnalyzeCoverQuality {
+		result.CoverQuality, err = checkCoverQuality(collectedMetadata, flagAnalyzeCoverRequirements)
+		if err != nil {
+			tui.Warn(err.Error())
+		}
+	}
+
+	if flagAnalyzeNaming {
+		fileScheme, err := unwrapKeys(flagAnalyzeFileScheme, false)
+		if err != nil {
+			return nil, err
+		}
+		dirScheme, err := unwrapKeys(flagAnalyzeDirScheme, true)
+		if err != nil {
+			return nil, err
+		}
+		result.Naming = checkNaming(collectedMetadata, result.ResolvedTags, fileScheme, dirScheme)
+	}
+
+	if genreMap != nil {
+		result.Genres, err = checkGenres(collectedMetadata, genreMap, flagAnalyzeGenreSplit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ruleSet != nil {
+		result.Findings = ruleSet.Evaluate(collectedMetadata)
+	}
+
+	if flagAnalyzeFix {
+		result.Fixes, err = planRemediation(result, collectedMetadata)
+		if err != nil {
+			tui.Warn(err.Error())
+		}
+	}
+
 	return &internal.GenericResult[analyzeResult]{
 		Operation: "analyze",
 		Data:      result,
@@ -98,6 +297,21 @@ func analyzeMetadata(target string) (*internal.GenericResult[analyzeResult], err
 	}, nil
 }
 
+// checkTechnicalConsistency reads the stream info of all files and checks each album for consistency
+func checkTechnicalConsistency(collectedMetadata map[string]map[string]string) ([]internal.Finding, error) {
+	var errs error
+	infos := make(map[string]internal.StreamInfo, len(collectedMetadata))
+	for file := range collectedMetadata {
+		info, err := internal.FetchStreamInfo(file)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		infos[file] = info
+	}
+	return internal.CheckTechnicalConsistency(infos), errs
+}
+
 func getMissingTags(albumMetadata map[string]map[string]string, tags map[string]bool) map[string][]string {
 	missing := make(map[string][]string)
 	for file, existentTags := range albumMetadata {
@@ -179,13 +393,8 @@ func getMultiValuedKeys(collectedMetadata map[string]map[string]string, tags []s
 }
 
 func analyzeAction(action *internal.GenericResult[analyzeResult]) error {
-	if flagMetaJsonOutput {
-		encoded, err := json.Marshal(action.Data)
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(encoded))
-		return nil
+	if flagAnalyzeFormat != reportFormatTable {
+		return writeAnalyzeReport(flagAnalyzeOutput, flagAnalyzeFormat, action)
 	}
 
 	if len(action.Data.MissingCovers) > 0 {
@@ -247,16 +456,113 @@ func analyzeAction(action *internal.GenericResult[analyzeResult]) error {
 		)
 	}
 
+	if action.Data.Covers != nil {
+		printCoverConsistency(*action.Data.Covers)
+	}
+
+	printFindings("Check", action.Data.Numbering)
+	printFindings("Check", action.Data.Hygiene)
+	printFindings("Check", action.Data.Technical)
+	printFindings("Check", action.Data.Naming)
+	printFindings("Check", action.Data.CoverQuality)
+	printFindings("Check", action.Data.Genres)
+	printFindings("Rule", action.Data.Findings)
+
+	// Print Resolved Tags table
+	if len(action.Data.ResolvedTags) > 0 {
+		var data [][]string
+		for dir, tagMap := range action.Data.ResolvedTags {
+			for tag, value := range tagMap {
+				data = append(data, []string{dir, tag, value})
+			}
+		}
+		tui.PrintTable(
+			"Resolved Tags",
+			[]string{"Directory", "Tag", "Value"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
 	action.Data.PrintSummary()
 
+	if flagAnalyzeFix {
+		return applyRemediation(action.Data.Fixes)
+	}
+
+	if flagResolveWriteBack {
+		return writeBackResolvedTags(action.Data.TagFixes)
+	}
+
+	return nil
+}
+
+// writeAnalyzeReport writes the report to the file or, if empty, to stdout
+func writeAnalyzeReport(file, format string, action *internal.GenericResult[analyzeResult]) error {
+	if file == "" {
+		return writeReport(os.Stdout, format, action.Data)
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := writeReport(out, format, action.Data); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func writeBackResolvedTags(fixes map[string]map[string]string) error {
+	if len(fixes) == 0 {
+		return nil
+	}
+
+	var data [][]string
+	for file, tags := range fixes {
+		for tag, value := range tags {
+			data = append(data, []string{file, tag, value})
+		}
+	}
+	tui.PrintTable("Write Tags", []string{"File", "Tag", "Value"}, data, tui.TableOpts{})
+
+	proceed, err := tui.Confirm("Proceed with writing resolved values?")
+	if err != nil {
+		return err
+	}
+
+	if !proceed {
+		return nil
+	}
+
+	for file, tags := range fixes {
+		if err := internal.SetMetadata(file, tags, true); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 type analyzeResult struct {
+	Target          string `json:",omitempty"`
 	MissingCovers   []string
 	MissingTags     map[string][]string
 	MultiValuedTags map[string]map[string][]string
 	UndesiredTags   map[string]map[string]string
+	ResolvedTags    map[string]map[string]string
+	TagFixes        map[string]map[string]string
+	Covers          *coverConsistency  `json:",omitempty"`
+	Numbering       []internal.Finding `json:",omitempty"`
+	Hygiene         []internal.Finding `json:",omitempty"`
+	Technical       []internal.Finding `json:",omitempty"`
+	Naming          []internal.Finding `json:",omitempty"`
+	CoverQuality    []internal.Finding `json:",omitempty"`
+	Genres          []internal.Finding `json:",omitempty"`
+	Findings        []internal.Finding `json:",omitempty"`
+	Fixes           []remediation      `json:",omitempty"`
 }
 
 func (ar *analyzeResult) PrintSummary() {
@@ -341,6 +647,26 @@ func (ar *analyzeResult) PrintSummary() {
 		summaryLines = append(summaryLines, fmt.Sprintf("%s %s %s", count, category, detail))
 	}
 
+	// Cover consistency
+	if ar.Covers != nil && !ar.Covers.IsEmpty() {
+		total := len(ar.Covers.MixedCovers) + len(ar.Covers.LowResolutionCovers) + len(ar.Covers.FolderCoverMismatches) + len(ar.Covers.MissingFrontCovers) +
+			len(ar.Covers.VisualOutliers) + len(ar.Covers.SharedCovers) + len(ar.Covers.UnreadableCovers)
+		count := numberStyle.Render(fmt.Sprintf("%d", total))
+		category := categoryStyle.Render("cover inconsistencies")
+		detail := detailStyle.Render(fmt.Sprintf("(%d mixed albums, %d low resolution, %d mismatches, %d without front cover, %d visual outliers, %d shared covers, %d albums with unreadable covers)",
+			len(ar.Covers.MixedCovers), len(ar.Covers.LowResolutionCovers), len(ar.Covers.FolderCoverMismatches), len(ar.Covers.MissingFrontCovers),
+			len(ar.Covers.VisualOutliers), len(ar.Covers.SharedCovers), len(ar.Covers.UnreadableCovers)))
+		summaryLines = append(summaryLines, fmt.Sprintf("%s %s %s", count, category, detail))
+	}
+
+	summaryLines = append(summaryLines, findingsSummary("check", ar.Numbering, numberStyle, categoryStyle, detailStyle)...)
+	summaryLines = append(summaryLines, findingsSummary("check", ar.Hygiene, numberStyle, categoryStyle, detailStyle)...)
+	summaryLines = append(summaryLines, findingsSummary("check", ar.Technical, numberStyle, categoryStyle, detailStyle)...)
+	summaryLines = append(summaryLines, findingsSummary("check", ar.Naming, numberStyle, categoryStyle, detailStyle)...)
+	summaryLines = append(summaryLines, findingsSummary("check", ar.CoverQuality, numberStyle, categoryStyle, detailStyle)...)
+	summaryLines = append(summaryLines, findingsSummary("check", ar.Genres, numberStyle, categoryStyle, detailStyle)...)
+	summaryLines = append(summaryLines, findingsSummary("rule", ar.Findings, numberStyle, categoryStyle, detailStyle)...)
+
 	if len(summaryLines) > 0 {
 		title := titleStyle.Render("Analysis Summary")
 		fmt.Println(title)
@@ -355,3 +681,98 @@ func (ar *analyzeResult) PrintSummary() {
 		fmt.Println()
 	}
 }
+
+func printCoverConsistency(covers coverConsistency) {
+	if len(covers.MixedCovers) > 0 {
+		var data [][]string
+		for dir, count := range covers.MixedCovers {
+			data = append(data, []string{dir, fmt.Sprintf("%d", count)})
+		}
+		tui.PrintTable(
+			"Mixed Embedded Covers",
+			[]string{"Directory", "Distinct Covers"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
+	if len(covers.LowResolutionCovers) > 0 {
+		var data [][]string
+		for file, resolution := range covers.LowResolutionCovers {
+			data = append(data, []string{file, resolution})
+		}
+		tui.PrintTable(
+			"Low Resolution Embedded Covers",
+			[]string{"File", "Embedded < Folder"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
+	if len(covers.FolderCoverMismatches) > 0 {
+		var data [][]string
+		for file, folderCover := range covers.FolderCoverMismatches {
+			data = append(data, []string{file, folderCover})
+		}
+		tui.PrintTable(
+			"Embedded Covers Differing From Folder Cover",
+			[]string{"File", "Folder Cover"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
+	if len(covers.MissingFrontCovers) > 0 {
+		var data [][]string
+		for _, file := range covers.MissingFrontCovers {
+			data = append(data, []string{file})
+		}
+		tui.PrintTable(
+			"Missing Front Covers",
+			[]string{"File"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
+	if len(covers.VisualOutliers) > 0 {
+		var data [][]string
+		for file, distance := range covers.VisualOutliers {
+			data = append(data, []string{file, fmt.Sprintf("%d", distance)})
+		}
+		tui.PrintTable(
+			"Embedded Covers Looking Different From Their Album",
+			[]string{"File", "Distance"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
+	if len(covers.SharedCovers) > 0 {
+		var data [][]string
+		for _, shared := range covers.SharedCovers {
+			data = append(data, []string{shared.Dir, shared.Album, shared.OtherDir, shared.OtherAlbum, fmt.Sprintf("%d", shared.Distance)})
+		}
+		tui.PrintTable(
+			"Albums Sharing A Cover",
+			[]string{"Directory", "Album", "Other Directory", "Other Album", "Distance"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+
+	if len(covers.UnreadableCovers) > 0 {
+		var data [][]string
+		for dir, errs := range covers.UnreadableCovers {
+			for _, err := range errs {
+				data = append(data, []string{dir, err})
+			}
+		}
+		tui.PrintTable(
+			"Unreadable Covers",
+			[]string{"Directory", "Error"},
+			data,
+			tui.TableOpts{},
+		)
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+const (
+	caseExceptionsFileName = "casing-exceptions.txt"
+	// caseLanguageAuto picks the casing rules by the LANGUAGE tag of each file
+	caseLanguageAuto = "auto"
+)
+
+var defaultCaseTags = []string{internal.TagTitle, internal.TagAlbum, internal.TagArtist}
+
+var caseCmd = &cobra.Command{
+	Use: "case [target]",
+	Aliases: []string{
+		"casing",
+		"titlecase",
+	},
+	Short: "Normalize the casing of titles, albums and artists",
+	Long: `Normalize the casing of titles, albums and artists.
+
+Title case capitalizes all words except small words such as "of" or "the" within a phrase, sentence case
+only capitalizes the first word of each phrase. The small words depend on the language, which is taken from
+the LANGUAGE tag of each file unless --language is given. German keeps the case of the words following the
+first word in sentence case, as nouns cannot be told apart from other words.
+
+Roman numerals are upper-cased, except for words that look like numerals in the language of the value, such
+as the Spanish "vi". In values that are neither all upper nor all lower case, acronyms such as "USA" and
+words such as "McCartney" are kept. Exceptions are written exactly as listed, they are read from
+--exceptions and from a file with one exception per line, defaulting to flac-mate/casing-exceptions.txt in
+the user's config directory if it exists.
+
+Changes are previewed and confirmed per album.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCase,
+}
+
+func init() {
+	metadataCmd.AddCommand(caseCmd)
+	caseCmd.Flags().StringVarP(&flagCaseStyle, "style", "s", string(internal.CaseStyleTitle), fmt.Sprintf("Case style %v", internal.CaseStyles))
+	caseCmd.Flags().StringVarP(&flagCaseLanguage, "language", "l", caseLanguageAuto, fmt.Sprintf("Language of the casing rules %v, or %q to use the LANGUAGE tag", slices.Sorted(maps.Keys(internal.CaseLanguages)), caseLanguageAuto))
+	caseCmd.Flags().StringSliceVarP(&flagCaseTags, "tags", "t", defaultCaseTags, "Tags to change the casing of")
+	caseCmd.Flags().StringSliceVarP(&flagCaseExceptions, "exceptions", "e", nil, "Words to write exactly as given, e.g. AC/DC,iPhone")
+	caseCmd.Flags().StringVar(&flagCaseExceptionsFile, "exceptions-file", "", fmt.Sprintf("File with one exception per line, defaults to flac-mate/%s in the user's config directory if it exists", caseExceptionsFileName))
+}
+
+func runCase(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := args[0]
+
+	style, err := internal.ParseCaseStyle(flagCaseStyle)
+	if err != nil {
+		return err
+	}
+
+	language := strings.ToLower(flagCaseLanguage)
+	if language != caseLanguageAuto {
+		if _, found := internal.CaseLanguages[language]; !found {
+			return fmt.Errorf("unknown language %q, valid languages are %v", flagCaseLanguage, slices.Sorted(maps.Keys(internal.CaseLanguages)))
+		}
+	}
+
+	exceptions, err := loadCaseExceptions(flagCaseExceptionsFile)
+	if err != nil {
+		return err
+	}
+	exceptions = append(exceptions, flagCaseExceptions...)
+
+	casing := internal.Casing{Style: style, Exceptions: exceptions}
+	action, err := changeCase(target, casing, language, flagCaseTags)
+	if err != nil {
+		return err
+	}
+
+	return action.Run()
+}
+
+// loadCaseExceptions reads the exceptions from the given file or, if no file is given, from the default
+// exceptions file if it exists. Empty lines and lines starting with # are skipped.
+func loadCaseExceptions(path string) ([]string, error) {
+	if path == "" {
+		var err error
+		path, err = configFile(caseExceptionsFileName)
+		if err != nil {
+			return nil, nil
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	} else {
+		path = pkg.GetExpandedFile(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var exceptions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			exceptions = append(exceptions, line)
+		}
+	}
+
+	return exceptions, scanner.Err()
+}
+
+// changeCase plans the casing changes of the tags of all files, grouped by album directory. With the auto
+// language, the casing rules are picked by the LANGUAGE tag of each file.
+// returns dir - file - { tag: change }
+func changeCase(target string, casing internal.Casing, language string, tags []string) (*internal.GenericResult[map[string]map[string]map[string]textChange], error) {
+	collectedMetadata, err := collectMetadataForFile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	// multi-valued tags such as ARTIST are cased value by value
+	rawValues, err := collectRawTagValues(collectedMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	upperTags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		upperTags = append(upperTags, strings.ToUpper(tag))
+	}
+
+	changes := make(map[string]map[string]map[string]textChange)
+	for file, values := range rawValues {
+		casing.Language = language
+		if language == caseLanguageAuto {
+			casing.Language = internal.LanguageCode(collectedMetadata[file][internal.TagLanguage])
+		}
+
+		fileChanges := transformValues(values, upperTags, casing.Apply)
+		if len(fileChanges) == 0 {
+			continue
+		}
+
+		dir := filepath.Dir(file)
+		if _, found := changes[dir]; !found {
+			changes[dir] = make(map[string]map[string]textChange)
+		}
+		changes[dir][file] = fileChanges
+	}
+
+	return &internal.GenericResult[map[string]map[string]map[string]textChange]{
+		Operation: "case",
+		Data:      changes,
+		Execute:   albumTextChangesAction,
+	}, nil
+}
+
+// albumTextChangesAction previews the changes of each album and writes them after confirmation
+func albumTextChangesAction(action *internal.GenericResult[map[string]map[string]map[string]textChange]) error {
+	if len(action.Data) == 0 {
+		successStyle := lipgloss.NewStyle().
+			Bold(true)
+		fmt.Println(successStyle.Render("✓ No changes needed!"))
+		return nil
+	}
+
+	var errs error
+	for _, dir := range slices.Sorted(maps.Keys(action.Data)) {
+		printTextChanges(dir, action.Data[dir])
+
+		proceed, err := tui.Confirm(fmt.Sprintf("Proceed with writing %d files of %s?", len(action.Data[dir]), filepath.Base(dir)))
+		if err != nil {
+			return multierr.Append(errs, err)
+		}
+
+		if proceed {
+			errs = multierr.Append(errs, writeTextChanges(action.Data[dir]))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+const genresFileName = "genres.yaml"
+
+var genresCmd = &cobra.Command{
+	Use: "genres [target]",
+	Aliases: []string{
+		"genre",
+	},
+	Short: "Rewrite genres according to a mapping to canonical genres",
+	Long: `Rewrite genres according to a mapping to canonical genres.
+
+The mapping is read from the YAML file given by --map, defaulting to flac-mate/genres.yaml in the user's
+config directory. Genres that only differ in case, spaces, hyphens and punctuation from a canonical genre,
+e.g. "Hip Hop", "hip-hop" and "HipHop", are mapped without listing them as aliases:
+
+  canonical: [Hip-Hop, Rap, Rock, Electronic]
+  aliases:
+    Hip-Hop: [Rap/Hip-Hop]
+    Electronic: [Electronica, EDM]
+  separators: ["/", ";", ","]   # used by --split, these are the defaults
+
+With --split, combined genres such as "Rock/EDM" that are not an alias as a whole are split and written as
+multiple GENRE values if all of their parts are known. Genres that are not known, e.g. "Singer/Songwriter",
+are kept as they are and reported. Every change is shown before
+anything is written. See "metadata analyze" to report non-canonical genres.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGenres,
+}
+
+func init() {
+	metadataCmd.AddCommand(genresCmd)
+	genresCmd.Flags().StringVarP(&flagGenresMap, "map", "m", "", fmt.Sprintf("Genre map file, defaults to flac-mate/%s in the user's config directory", genresFileName))
+	genresCmd.Flags().BoolVarP(&flagGenresSplit, "split", "s", false, "Split combined genres into multiple GENRE values")
+}
+
+func runGenres(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := args[0]
+
+	genreMap, err := loadGenreMap(flagGenresMap)
+	if err != nil {
+		return err
+	}
+	if genreMap == nil {
+		path, _ := configFile(genresFileName)
+		return fmt.Errorf("no genre map given and %q does not exist", path)
+	}
+
+	action, err := rewriteGenres(target, genreMap, flagGenresSplit)
+	if err != nil {
+		return err
+	}
+
+	return action.Run()
+}
+
+// loadGenreMap loads the genre map from the given file or, if no file is given, from the default genre
+// map file if it exists. Returns nil if there is no genre map.
+func loadGenreMap(path string) (*internal.GenreMap, error) {
+	if path != "" {
+		return internal.LoadGenreMap(pkg.GetExpandedFile(path))
+	}
+
+	path, err := configFile(genresFileName)
+	if err != nil {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return internal.LoadGenreMap(path)
+}
+
+// collectGenres fetches all GENRE values of the files that have a genre, as the collected metadata only
+// holds a single value per tag
+// returns file - GENRE values
+func collectGenres(collectedMetadata map[string]map[string]string) (map[string][]string, error) {
+	genres := make(map[string][]string)
+	for file, metadata := range collectedMetadata {
+		if _, found := metadata[internal.TagGenre]; !found {
+			continue
+		}
+
+		values, err := internal.FetchTagValues(file, internal.TagGenre)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		genres[file] = values
+	}
+
+	return genres, nil
+}
+
+// checkGenres reports the genres that are not spelled like a canonical genre, with split combined genres
+// are suggested to be split
+func checkGenres(collectedMetadata map[string]map[string]string, genreMap *internal.GenreMap, split bool) ([]internal.Finding, error) {
+	genres, err := collectGenres(collectedMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return genreMap.CheckGenres(genres, split), nil
+}
+
+// genreChange are the GENRE values of a file before and after the rewrite
+type genreChange struct {
+	Before []string
+	After  []string
+	// Unknown lists the genres that are not in the genre map
+	Unknown []string `json:",omitempty"`
+}
+
+// rewriteGenres plans the canonical GENRE values of all files
+// returns file - change
+func rewriteGenres(target string, genreMap *internal.GenreMap, split bool) (*internal.GenericResult[map[string]genreChange], error) {
+	collectedMetadata, err := collectMetadataForFile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := collectGenres(collectedMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]genreChange)
+	for file, values := range genres {
+		canonical, known := genreMap.CanonicalizeAll(values, split)
+
+		var unknown []string
+		if !known {
+			for _, genre := range canonical {
+				if _, found := genreMap.Lookup(genre); !found {
+					unknown = append(unknown, genre)
+				}
+			}
+		}
+
+		if slices.Equal(values, canonical) && len(unknown) == 0 {
+			continue
+		}
+		changes[file] = genreChange{Before: values, After: canonical, Unknown: unknown}
+	}
+
+	return &internal.GenericResult[map[string]genreChange]{
+		Operation: "genres",
+		Data:      changes,
+		Execute:   genresAction,
+	}, nil
+}
+
+// genresAction previews the changed genres and writes them after confirmation, unknown genres are reported
+func genresAction(action *internal.GenericResult[map[string]genreChange]) error {
+	var tableData, unknownData [][]string
+	for _, file := range slices.Sorted(maps.Keys(action.Data)) {
+		change := action.Data[file]
+		if len(change.Unknown) > 0 {
+			unknownData = append(unknownData, []string{file, strings.Join(change.Unknown, "; ")})
+		}
+		if !slices.Equal(change.Before, change.After) {
+			tableData = append(tableData, []string{file, quoteValues(change.Before), quoteValues(change.After)})
+		}
+	}
+
+	if len(unknownData) > 0 {
+		tui.PrintTable("Unknown Genres", []string{"File", "Genres"}, unknownData, tui.TableOpts{})
+	}
+
+	if len(tableData) == 0 {
+		successStyle := lipgloss.NewStyle().
+			Bold(true)
+		fmt.Println(successStyle.Render("✓ No changes needed!"))
+		return nil
+	}
+
+	tui.PrintTable("Genres", []string{"File", "Before", "After"}, tableData, tui.TableOpts{})
+
+	proceed, err := tui.Confirm(fmt.Sprintf("Proceed with writing %d files?", len(tableData)))
+	if err != nil {
+		return err
+	}
+
+	if !proceed {
+		return nil
+	}
+
+	var errs error
+	for _, file := range slices.Sorted(maps.Keys(action.Data)) {
+		change := action.Data[file]
+		if slices.Equal(change.Before, change.After) {
+			continue
+		}
+		if err := internal.SetTagValues(file, internal.TagGenre, change.After); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", file, err))
+		}
+	}
+
+	return errs
+}
+
+// quoteValues quotes and joins the values of a multi-valued tag
+func quoteValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+var normalizeCmd = &cobra.Command{
+	Use: "normalize [target]",
+	Aliases: []string{
+		"norm",
+	},
+	Short: "Trim, NFC-normalize, strip control characters and repair double-encoded UTF-8 in tag values",
+	Long: `Trim, NFC-normalize, strip control characters and repair double-encoded UTF-8 in tag values.
+
+Values are trimmed and runs of spaces are collapsed. Characters decomposed by some taggers, e.g. "o" followed
+by a combining diaeresis, are composed (Unicode normalization form C). Control characters other than line
+breaks are removed and text that has been decoded as Latin-1 or Windows-1252 and encoded as UTF-8 again,
+such as "BjÃ¶rk", is repaired. Every change is shown before anything is written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNormalize,
+}
+
+func init() {
+	metadataCmd.AddCommand(normalizeCmd)
+	normalizeCmd.Flags().StringSliceVarP(&flagMetaNormalizeTags, "tags", "t", nil, "Only normalize these tags, defaults to all tags")
+}
+
+func runNormalize(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := args[0]
+
+	action, err := normalizeMetadata(target, flagMetaNormalizeTags)
+	if err != nil {
+		return err
+	}
+
+	return action.Run()
+}
+
+// textChange is the values of a tag before and after a transformation
+type textChange struct {
+	Before []string
+	After  []string
+}
+
+// normalizeMetadata plans the normalization of the tag values of all files, synthetic tags are skipped
+// returns file - { tag: change }
+func normalizeMetadata(target string, tags []string) (*internal.GenericResult[map[string]map[string]textChange], error) {
+	collectedMetadata, err := collectMetadataForFile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	rawValues, err := collectRawTagValues(collectedMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tag := range tags {
+		tags[i] = strings.ToUpper(tag)
+	}
+
+	changes := make(map[string]map[string]textChange)
+	for file, values := range rawValues {
+		if fileChanges := transformValues(values, tags, internal.NormalizeText); len(fileChanges) > 0 {
+			changes[file] = fileChanges
+		}
+	}
+
+	return &internal.GenericResult[map[string]map[string]textChange]{
+		Operation: "normalize",
+		Data:      changes,
+		Execute:   textChangesAction,
+	}, nil
+}
+
+// collectRawTagValues reads all values of all tags of the collected files as they are, see
+// internal.FetchAllTagValues
+// returns file - { tag: values }
+func collectRawTagValues(collectedMetadata map[string]map[string]string) (map[string]map[string][]string, error) {
+	var errs error
+	rawValues := make(map[string]map[string][]string, len(collectedMetadata))
+	for file := range collectedMetadata {
+		values, err := internal.FetchAllTagValues(file)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		rawValues[file] = values
+	}
+
+	return rawValues, errs
+}
+
+// transformValues applies the transformation to every value of the tags, synthetic tags and tags not in
+// the list are skipped. An empty list selects all tags.
+// values is tag - values, returns tag - change of the changed tags
+func transformValues(values map[string][]string, tags []string, transform func(string) string) map[string]textChange {
+	changes := make(map[string]textChange)
+	for tag, before := range values {
+		if strings.HasPrefix(tag, "_") || (len(tags) > 0 && !slices.Contains(tags, tag)) {
+			continue
+		}
+
+		after := make([]string, 0, len(before))
+		for _, value := range before {
+			after = append(after, transform(value))
+		}
+		if !slices.Equal(before, after) {
+			changes[tag] = textChange{Before: before, After: after}
+		}
+	}
+
+	return changes
+}
+
+// textChangesAction previews the changed values of each file and writes them after confirmation
+func textChangesAction(action *internal.GenericResult[map[string]map[string]textChange]) error {
+	if len(action.Data) == 0 {
+		successStyle := lipgloss.NewStyle().
+			Bold(true)
+		fmt.Println(successStyle.Render(fmt.Sprintf("✓ Nothing to %s!", action.Operation)))
+		return nil
+	}
+
+	printTextChanges(operationTitle(action.Operation), action.Data)
+
+	proceed, err := tui.Confirm(fmt.Sprintf("Proceed with writing %d files?", len(action.Data)))
+	if err != nil {
+		return err
+	}
+
+	if !proceed {
+		return nil
+	}
+
+	return writeTextChanges(action.Data)
+}
+
+// printTextChanges prints a table of the values before and after the changes. The values are quoted to
+// make whitespace and control characters visible.
+// changes is file - { tag: change }
+func printTextChanges(title string, changes map[string]map[string]textChange) {
+	var tableData [][]string
+	for _, file := range slices.Sorted(maps.Keys(changes)) {
+		for _, tag := range slices.Sorted(maps.Keys(changes[file])) {
+			change := changes[file][tag]
+			tableData = append(tableData, []string{file, tag, quoteValues(change.Before), quoteValues(change.After)})
+		}
+	}
+
+	tui.PrintTable(title, []string{"File", "Tag", "Before", "After"}, tableData, tui.TableOpts{})
+}
+
+// writeTextChanges writes the changed values of each file, all values of a changed tag are written back
+// changes is file - { tag: change }
+func writeTextChanges(changes map[string]map[string]textChange) error {
+	var errs error
+	for _, file := range slices.Sorted(maps.Keys(changes)) {
+		for _, tag := range slices.Sorted(maps.Keys(changes[file])) {
+			if err := internal.SetTagValues(file, tag, changes[file][tag].After); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("%s: %w", file, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// operationTitle capitalizes the first letter of an operation for use as a table title
+func operationTitle(operation string) string {
+	if operation == "" {
+		return operation
+	}
+	return strings.ToUpper(operation[:1]) + operation[1:]
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/soerenschneider/flac-mate/internal"
+)
+
+func TestTransformValues(t *testing.T) {
+	values := map[string][]string{
+		internal.TagArtist:            {"Björk ", "Sigur  Rós"},
+		internal.TagGenre:             {"Rock", "Pop"},
+		internal.TagTitle:             {"Jóga\t"},
+		"LYRICS":                      {"First line\nSecond line"},
+		internal.SyntheticFilePathTag: {" /a/1.flac"},
+	}
+
+	tests := []struct {
+		name string
+		tags []string
+		want map[string]textChange
+	}{
+		{
+			name: "all tags",
+			want: map[string]textChange{
+				internal.TagArtist: {Before: []string{"Björk ", "Sigur  Rós"}, After: []string{"Björk", "Sigur Rós"}},
+				internal.TagTitle:  {Before: []string{"Jóga\t"}, After: []string{"Jóga"}},
+			},
+		},
+		{
+			name: "selected tags",
+			tags: []string{internal.TagTitle, internal.TagGenre},
+			want: map[string]textChange{
+				internal.TagTitle: {Before: []string{"Jóga\t"}, After: []string{"Jóga"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transformValues(values, tt.tags, internal.NormalizeText); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transformValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/soerenschneider/flac-mate/internal"
 	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
 	"github.com/spf13/cobra"
 )
 
@@ -22,19 +25,98 @@ var addPictureCmd = &cobra.Command{
 	RunE:  runAddPicture,
 }
 
+const (
+	pictureAddModeReplaceAll  = "replace-all"
+	pictureAddModeReplaceType = "replace"
+	pictureAddModeAppend      = "append"
+)
+
+var pictureAddModes = []string{pictureAddModeReplaceAll, pictureAddModeReplaceType, pictureAddModeAppend}
+
 func init() {
 	metadataCmd.AddCommand(addPictureCmd)
 	addPictureCmd.Flags().StringVarP(&flagMetaPictureFile, "picture", "p", "", "Picture file to add to the flac")
+	addPictureCmd.Flags().StringVarP(&flagMetaPictureType, "type", "t", "front", fmt.Sprintf("Picture type %v", internal.PictureTypeNames()))
+	addPictureCmd.Flags().StringVarP(&flagMetaPictureDescription, "description", "D", "", "Description of the picture")
+	addPictureCmd.Flags().StringVarP(&flagMetaPictureAddMode, "mode", "m", pictureAddModeReplaceAll, fmt.Sprintf("How to treat embedded pictures %v", pictureAddModes))
+	addPictureCmd.Flags().BoolVarP(&flagMetaPictureAddPerAlbum, "per-album", "a", false, "Embed the folder cover of each album directory below target as front cover into the album's tracks instead of a single picture")
+	addPictureCmd.Flags().BoolVarP(&flagMetaPictureOptimize, "optimize", "o", false, "Scale down and recompress the picture to a baseline JPEG before embedding, the original file is left untouched")
+	addPictureCmd.Flags().IntVar(&flagMetaPictureMaxDimension, "max-dimension", pkg.DefaultMaxDimension, "Maximum width and height of optimized pictures, 0 keeps the original size")
+	addPictureCmd.Flags().IntVar(&flagMetaPictureQuality, "quality", pkg.DefaultJpegQuality, "JPEG quality of optimized pictures (1-100)")
 }
 
 func runAddPicture(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
+	pictureType, err := internal.ParsePictureType(flagMetaPictureType)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(pictureAddModes, flagMetaPictureAddMode) {
+		return fmt.Errorf("unknown mode %q, valid modes are %v", flagMetaPictureAddMode, pictureAddModes)
+	}
+
+	if flagMetaPictureAddPerAlbum {
+		return runAddAlbumCovers(args[0], pictureType)
+	}
+
+	// make sure the picture is valid before any embedded picture gets removed
+	if isValid, _, _, _ := pkg.IsValidImage(flagMetaPictureFile); !isValid {
+		return fmt.Errorf("%q is not a supported image", flagMetaPictureFile)
+	}
+
 	target := args[0]
 	result, err := addPicture(target, flagMetaPictureFile)
 	if err != nil {
 		return err
 	}
+	result.Data.PictureType = pictureType
+	result.Data.Description = flagMetaPictureDescription
+	result.Data.Mode = flagMetaPictureAddMode
+
+	if flagMetaPictureOptimize {
+		optimized, err := pkg.WriteOptimizedImage(flagMetaPictureFile, "", pkg.OptimizeOpts{
+			MaxDimension: flagMetaPictureMaxDimension,
+			Quality:      flagMetaPictureQuality,
+		})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = os.Remove(optimized)
+		}()
+		result.Data.ImageFile = optimized
+	}
+
+	return result.Run()
+}
+
+func runAddAlbumCovers(target string, pictureType int) error {
+	if flagMetaPictureFile != "" {
+		return fmt.Errorf("--picture can not be used together with --per-album")
+	}
+
+	if pictureType != internal.PictureTypeFront {
+		return fmt.Errorf("--per-album only embeds front covers")
+	}
+
+	var opts *pkg.OptimizeOpts
+	if flagMetaPictureOptimize {
+		opts = &pkg.OptimizeOpts{
+			MaxDimension: flagMetaPictureMaxDimension,
+			Quality:      flagMetaPictureQuality,
+		}
+	}
+
+	result, err := planAlbumCovers(strings.TrimSuffix(pkg.GetExpandedFile(target), "/"), opts, flagMetaPictureAddMode)
+	if result != nil {
+		defer result.Data.Cleanup()
+	}
+	if err != nil {
+		return err
+	}
+	result.Data.Description = flagMetaPictureDescription
 
 	return result.Run()
 }
@@ -48,7 +130,9 @@ func addPicture(target string, picture string) (*internal.GenericResult[addImage
 	result := &internal.GenericResult[addImageOp]{
 		Operation: "add-picture",
 		Data: addImageOp{
-			ImageFile: picture,
+			ImageFile:   picture,
+			PictureType: internal.PictureTypeFront,
+			Mode:        pictureAddModeReplaceAll,
 		},
 		Execute: picturesAddAction,
 	}
@@ -80,8 +164,11 @@ func addPicture(target string, picture string) (*internal.GenericResult[addImage
 }
 
 type addImageOp struct {
-	ImageFile string
-	Files     []string
+	ImageFile   string
+	PictureType int
+	Description string
+	Mode        string
+	Files       []string
 }
 
 func picturesAddAction(action *internal.GenericResult[addImageOp]) error {
@@ -95,6 +182,15 @@ func picturesAddAction(action *internal.GenericResult[addImageOp]) error {
 	}
 
 	tui.PrintTable("Affected Files", []string{"File"}, tableData, tui.TableOpts{})
+	tui.Muted(fmt.Sprintf("Adding %q as %s picture (mode: %s)", flagMetaPictureFile, internal.PictureTypeName(action.Data.PictureType), action.Data.Mode))
+	if action.Data.ImageFile != flagMetaPictureFile {
+		if original, optimized, err := fileSizes(flagMetaPictureFile, action.Data.ImageFile); err == nil {
+			tui.Muted(fmt.Sprintf("Optimized picture from %s to %s", pkg.HumanSize(original), pkg.HumanSize(optimized)))
+		}
+	}
+	if _, format, _, err := pkg.IsValidImage(action.Data.ImageFile); err == nil && !pkg.IsEmbeddableFormat(format) {
+		tui.Muted(fmt.Sprintf("Converting %s picture to JPEG or PNG before embedding", strings.ToUpper(format)))
+	}
 
 	proceed, err := tui.Confirm("Proceed with adding pictures?")
 	if err != nil {
@@ -106,10 +202,45 @@ func picturesAddAction(action *internal.GenericResult[addImageOp]) error {
 	}
 
 	for _, file := range action.Data.Files {
-		if err := internal.SetPicture(file, action.Data.ImageFile); err != nil {
+		if err := embedPicture(file, action.Data); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// embedPicture embeds the picture of the operation into a single file, treating existing pictures according to the mode.
+func embedPicture(file string, op addImageOp) error {
+	switch op.Mode {
+	case pictureAddModeReplaceAll:
+		if err := internal.DeletePictures(file); err != nil {
+			return err
+		}
+	case pictureAddModeReplaceType:
+		images, err := internal.GetFlacImages(file)
+		if err != nil {
+			return err
+		}
+		filter := internal.PictureFilter{Types: []int{op.PictureType}}
+		if err := internal.DeletePictureBlocks(file, filter.Filter(images)); err != nil {
+			return err
+		}
+	}
+
+	return internal.AddPicture(file, op.ImageFile, op.PictureType, op.Description)
+}
+
+func fileSizes(a, b string) (int64, int64, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return infoA.Size(), infoB.Size(), nil
+}
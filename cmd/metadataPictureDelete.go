@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/soerenschneider/flac-mate/internal"
@@ -24,20 +26,38 @@ var delPictureCmd = &cobra.Command{
 		"delete-pic",
 		"delete-pics",
 	},
-	Short: "Deletes a picture from a flac file",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runDelPicture,
+	Short: "Deletes pictures from a flac file",
+	Long: `Deletes pictures from a flac file.
+
+Without any of the --type, --index or --mime flags, all pictures are deleted. If multiple flags are given,
+a picture needs to match all of them. Indexes refer to the order of the pictures as shown by pictures-list.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDelPicture,
 }
 
 func init() {
 	metadataCmd.AddCommand(delPictureCmd)
+	delPictureCmd.Flags().StringSliceVarP(&flagMetaPictureTypes, "type", "t", nil, fmt.Sprintf("Only delete pictures of these types %v", internal.PictureTypeNames()))
+	delPictureCmd.Flags().IntSliceVarP(&flagMetaPictureIndexes, "index", "i", nil, "Only delete pictures at these (1-based) indexes")
+	delPictureCmd.Flags().StringSliceVarP(&flagMetaPictureMIMETypes, "mime", "m", nil, "Only delete pictures with these MIME types, e.g. image/png")
 }
 
 func runDelPicture(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
+	pictureTypes, err := internal.ParsePictureTypes(flagMetaPictureTypes)
+	if err != nil {
+		return err
+	}
+
+	filter := internal.PictureFilter{
+		Types:     pictureTypes,
+		Indexes:   flagMetaPictureIndexes,
+		MIMETypes: flagMetaPictureMIMETypes,
+	}
+
 	target := args[0]
-	action, err := delete(target)
+	action, err := deletePictures(target, filter)
 	if err != nil {
 		return err
 	}
@@ -45,55 +65,78 @@ func runDelPicture(cmd *cobra.Command, args []string) error {
 	return action.Run()
 }
 
-func delete(target string) (*internal.GenericResult[[]string], error) {
+// deletePictures collects the pictures selected by the filter
+// returns file - [pictures]
+func deletePictures(target string, filter internal.PictureFilter) (*internal.GenericResult[map[string][]internal.FlacImage], error) {
 	info, err := os.Stat(target)
 	if err != nil {
 		return nil, err
 	}
 
-	action := &internal.GenericResult[[]string]{
+	action := &internal.GenericResult[map[string][]internal.FlacImage]{
 		Operation: "pic-delete",
-		Data:      make([]string, 0),
+		Data:      make(map[string][]internal.FlacImage),
 		Execute:   picturesDeleteAction,
 	}
 
+	var files []string
 	if !info.IsDir() {
-		action.Data = []string{target}
-		return action, nil
-	}
+		files = []string{target}
+	} else {
+		err = filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
 
-	err = filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+			if info.IsDir() || !strings.HasSuffix(strings.ToLower(info.Name()), ".flac") {
+				return nil
+			}
+
+			files = append(files, path)
 
-		if info.IsDir() || !strings.HasSuffix(strings.ToLower(info.Name()), ".flac") {
 			return nil
-		}
+		})
 
-		action.Data = append(action.Data, path)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		return nil
-	})
+	for _, file := range files {
+		images, err := internal.GetFlacImages(file)
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
+		matched := filter.Filter(images)
+		if len(matched) > 0 {
+			action.Data[file] = matched
+		}
 	}
 
 	return action, nil
 }
 
-func picturesDeleteAction(action *internal.GenericResult[[]string]) error {
+func picturesDeleteAction(action *internal.GenericResult[map[string][]internal.FlacImage]) error {
 	if len(action.Data) == 0 {
+		tui.Info("No matching pictures found")
 		return nil
 	}
 
+	files := make([]string, 0, len(action.Data))
+	for file := range action.Data {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
 	var tableData [][]string
-	for _, file := range action.Data {
-		tableData = append(tableData, []string{file})
+	for _, file := range files {
+		for _, img := range action.Data[file] {
+			tableData = append(tableData, []string{file, img.Type, img.MIMEType, img.Description})
+		}
 	}
 
-	tui.PrintTable("Affected Files", []string{"File"}, tableData, tui.TableOpts{})
+	tui.PrintTable("Affected Pictures", []string{"File", "Type", "MIME Type", "Description"}, tableData, tui.TableOpts{})
 
 	proceed, err := tui.Confirm("Proceed with deleting pictures?")
 	if err != nil {
@@ -104,8 +147,8 @@ func picturesDeleteAction(action *internal.GenericResult[[]string]) error {
 		return nil
 	}
 
-	for _, flac := range action.Data {
-		if err := internal.DeletePictures(flac); err != nil {
+	for _, flac := range files {
+		if err := internal.DeletePictureBlocks(flac, action.Data[flac]); err != nil {
 			return err
 		}
 	}
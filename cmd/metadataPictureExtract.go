@@ -1,15 +1,18 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/rs/zerolog/log"
+	"github.com/soerenschneider/flac-mate/internal"
 	"github.com/soerenschneider/flac-mate/internal/tui"
 	"github.com/soerenschneider/flac-mate/pkg"
 	"github.com/spf13/cobra"
@@ -23,19 +26,33 @@ var metaPictureExtractCmd = &cobra.Command{
 		"extract-picture",
 		"extract-pic",
 	},
-	Short: "Extract a picture from a flac file to",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runMetaPictureExtract,
+	Short: "Extract embedded pictures of flac files to their directory",
+	Long: `Extract embedded pictures of flac files to their directory.
+
+By default, the front cover of the first file that has one is written as cover.<ext> to directories
+without a cover. Pictures are named by their type (cover, back, artist, ...) and numbered if a type
+occurs more than once. Byte-identical pictures are only written once.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMetaPictureExtract,
 }
 
 func init() {
 	metadataCmd.AddCommand(metaPictureExtractCmd)
 	metaPictureExtractCmd.Flags().StringVarP(&flagMetaPictureFile, "picture", "p", "", "Picture file to add to the flac")
+	metaPictureExtractCmd.Flags().BoolVarP(&flagMetaPictureExtractAll, "all", "a", false, "Extract pictures of all types instead of only the front cover")
+	metaPictureExtractCmd.Flags().BoolVarP(&flagMetaPictureAllFiles, "all-files", "f", false, "Extract pictures from every file instead of only the first one that has pictures")
+	metaPictureExtractCmd.Flags().BoolVarP(&flagMetaPictureOverwrite, "overwrite", "o", false, "Overwrite existing folder images")
+	metaPictureExtractCmd.Flags().BoolVarP(&flagMetaPictureInteractive, "interactive", "i", false, "Choose the existing folder cover if it is ambiguous, choosing none extracts the embedded cover")
+	addPreviewFlags(metaPictureExtractCmd)
 }
 
 func runMetaPictureExtract(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
+	if err := validatePreviewFlags(cmd); err != nil {
+		return err
+	}
+
 	target := pkg.GetExpandedFile(args[0])
 
 	if info, err := os.Stat(target); err != nil || !info.IsDir() {
@@ -105,23 +122,44 @@ func extractCoverForDir(basedir string, filenames []string) error {
 		return nil // not a music dir
 	}
 
-	// Handle cover image renaming
-	cover, err := pkg.GetMainCover(basedir, collectedImages)
-	if cover != "" && err == nil {
-		tui.Info(fmt.Sprintf("%q already has a cover defined: %s", basedir, cover))
-		return err
+	// Without exporting all pictures, only directories without a cover are of interest
+	if !flagMetaPictureExtractAll && !flagMetaPictureOverwrite {
+		cover, err := chooseFolderCover(basedir, collectedImages, flagMetaPictureInteractive, true)
+		if err != nil {
+			return err
+		}
+		if cover != "" {
+			tui.Info(fmt.Sprintf("%q already has a cover defined: %s", basedir, cover))
+			return nil
+		}
+	}
+
+	extractor := newPictureExtractor(basedir)
+	if !flagMetaPictureOverwrite {
+		// existing folder images count as already extracted
+		for _, image := range collectedImages {
+			if hash, err := fileHash(filepath.Join(basedir, image)); err == nil {
+				extractor.seen[hash] = true
+			}
+		}
 	}
 
 	var errs error
 	for _, file := range flacFiles {
 		path := filepath.Join(basedir, file)
-		_, err := tryExtractCover(basedir, path)
+		extracted, err := extractor.extract(path)
 		if err != nil {
 			errs = multierr.Append(errs, err)
 			continue
-		} else {
-			return errs
 		}
+
+		if extracted > 0 && !flagMetaPictureAllFiles {
+			return nil
+		}
+	}
+
+	if extractor.found == 0 && errs == nil {
+		return ErrNoCoverFound
 	}
 
 	return errs
@@ -129,32 +167,130 @@ func extractCoverForDir(basedir string, filenames []string) error {
 
 var ErrNoCoverFound = errors.New("no cover image found in metadata")
 
-func tryExtractCover(basedir, path string) (string, error) {
-	tmp, err := os.CreateTemp(basedir, "cover-*")
+// pictureExtractor exports the embedded pictures of the files of a directory, skipping pictures that
+// have already been exported from another file.
+type pictureExtractor struct {
+	basedir string
+	seen    map[string]bool
+	counts  map[int]int
+	found   int
+}
+
+func newPictureExtractor(basedir string) *pictureExtractor {
+	return &pictureExtractor{
+		basedir: basedir,
+		seen:    make(map[string]bool),
+		counts:  make(map[int]int),
+	}
+}
+
+// extract exports the pictures of a single flac file and returns the number of pictures that were found.
+func (e *pictureExtractor) extract(path string) (int, error) {
+	images, err := internal.GetFlacImages(path)
 	if err != nil {
-		return "", err
+		return 0, err
+	}
+
+	if !flagMetaPictureExtractAll {
+		images = frontCovers(images)
+	}
+	e.found += len(images)
+
+	var errs error
+	for _, img := range images {
+		if err := e.extractPicture(path, img); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	return len(images), errs
+}
+
+func (e *pictureExtractor) extractPicture(path string, img internal.FlacImage) error {
+	tmp, err := os.CreateTemp(e.basedir, "cover-*")
+	if err != nil {
+		return err
 	}
 	_ = tmp.Close()
 	defer func() {
 		_ = os.Remove(tmp.Name())
 	}()
 
-	if err := exec.Command("metaflac", "--export-picture-to="+tmp.Name(), path).Run(); err != nil {
-		return "", err
+	if err := internal.ExportPicture(path, img.BlockNumber, tmp.Name()); err != nil {
+		return err
 	}
 
+	hash, err := fileHash(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if e.seen[hash] {
+		return nil
+	}
+	e.seen[hash] = true
+
 	ext, err := imageExt(tmp.Name())
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	outPath := filepath.Join(basedir, "cover"+ext)
-	log.Info().Str("path", outPath).Msg("extracting cover")
+	e.counts[img.TypeID()]++
+	name := pictureFileName(img.TypeID(), e.counts[img.TypeID()])
+	outPath := filepath.Join(e.basedir, name+ext)
+	if _, err := os.Stat(outPath); err == nil && !flagMetaPictureOverwrite {
+		tui.Info(fmt.Sprintf("keeping existing %q", outPath))
+		return nil
+	}
+
+	log.Info().Str("path", outPath).Msg("extracting picture")
 	if err := os.Rename(tmp.Name(), outPath); err != nil {
-		return "", fmt.Errorf("saving cover: %w", err)
+		return fmt.Errorf("saving picture: %w", err)
+	}
+
+	return nil
+}
+
+// frontCovers returns the front covers of the images, or the first image if there is no front cover.
+func frontCovers(images []internal.FlacImage) []internal.FlacImage {
+	if len(images) == 0 {
+		return nil
 	}
 
-	return outPath, nil
+	filter := internal.PictureFilter{Types: []int{internal.PictureTypeFront}}
+	covers := filter.Filter(images)
+	if len(covers) == 0 {
+		return images[:1]
+	}
+	return covers
+}
+
+// pictureFileName returns the name of an exported picture without extension, e.g. "cover", "back" or "back-2"
+func pictureFileName(pictureType int, index int) string {
+	name := internal.PictureTypeName(pictureType)
+	if pictureType == internal.PictureTypeFront {
+		name = "cover"
+	}
+
+	if index > 1 {
+		return fmt.Sprintf("%s-%d", name, index)
+	}
+	return name
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // imageExt sniffs the first few bytes of a file to determine the image format.
@@ -181,6 +317,10 @@ func imageExt(path string) (string, error) {
 		return ".gif", nil
 	case string(buf[:4]) == "RIFF" && string(buf[8:12]) == "WEBP":
 		return ".webp", nil
+	case string(buf[:2]) == "BM":
+		return ".bmp", nil
+	case string(buf[:4]) == "II*\x00" || string(buf[:4]) == "MM\x00*":
+		return ".tiff", nil
 	default:
 		return "", fmt.Errorf("unrecognised image format")
 	}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/soerenschneider/flac-mate/internal"
 	"github.com/soerenschneider/flac-mate/internal/tui"
 	"github.com/spf13/cobra"
@@ -31,11 +35,16 @@ var listPicturesCmd = &cobra.Command{
 
 func init() {
 	metadataCmd.AddCommand(listPicturesCmd)
+	addPreviewFlags(listPicturesCmd)
 }
 
 func runListPicture(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
+	if err := validatePreviewFlags(cmd); err != nil {
+		return err
+	}
+
 	target := args[0]
 
 	result, err := fetchImages(target)
@@ -98,6 +107,7 @@ func fetchImages(target string) (*internal.GenericResult[map[string][]internal.F
 func picturesListAction(action *internal.GenericResult[map[string][]internal.FlacImage]) error {
 	var flacImageHeaders = []string{
 		"File",
+		"#",
 		"Type",
 		"MIME Type",
 		"Description",
@@ -110,9 +120,10 @@ func picturesListAction(action *internal.GenericResult[map[string][]internal.Fla
 
 	var tableData [][]string
 	for file, images := range action.Data {
-		for _, img := range images {
+		for i, img := range images {
 			row := []string{
 				file,
+				strconv.Itoa(i + 1),
 				img.Type,
 				img.MIMEType,
 				img.Description,
@@ -127,5 +138,57 @@ func picturesListAction(action *internal.GenericResult[map[string][]internal.Fla
 	}
 
 	tui.PrintTable("Pictures", flacImageHeaders, tableData, tui.TableOpts{})
+
+	if flagPreview {
+		printPicturePreviews(action.Data)
+	}
 	return nil
 }
+
+// printPicturePreviews renders the embedded pictures and the folder cover of each directory. Pictures
+// that are identical to an already rendered picture are only referenced.
+func printPicturePreviews(images map[string][]internal.FlacImage) {
+	files := make([]string, 0, len(images))
+	for file := range images {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	headingStyle := lipgloss.NewStyle().Bold(true)
+	shown := make(map[string]string)
+	preview := func(heading string, data []byte) {
+		hash := dataHash(data)
+		if previous, found := shown[hash]; found {
+			tui.Muted(fmt.Sprintf("%s: same as %s", heading, previous))
+			return
+		}
+		shown[hash] = heading
+
+		fmt.Println(headingStyle.Render(heading))
+		if err := printPreview(data); err != nil {
+			tui.Warn(err.Error())
+		}
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if !dirs[dir] {
+			dirs[dir] = true
+			if cover, found, err := fetchFolderCover(dir); err == nil && found {
+				if data, err := os.ReadFile(cover.Path); err == nil {
+					preview(cover.Path, data)
+				}
+			}
+		}
+
+		for i, img := range images[file] {
+			data, err := internal.ReadPicture(file, img.BlockNumber)
+			if err != nil {
+				tui.Warn(err.Error())
+				continue
+			}
+			preview(fmt.Sprintf("%s #%d (%s)", file, i+1, internal.PictureTypeName(img.TypeID())), data)
+		}
+	}
+}
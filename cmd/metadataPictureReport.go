@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+const defaultMaxPictureBytes = 500_000
+
+var reportPicturesCmd = &cobra.Command{
+	Use: "pictures-report [target]",
+	Aliases: []string{
+		"pics-report",
+		"pic-report",
+		"picture-report",
+		"report-pictures",
+	},
+	Short: "Reports the space used by embedded pictures, oversized and duplicated pictures and the possible savings",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReportPictures,
+}
+
+func init() {
+	metadataCmd.AddCommand(reportPicturesCmd)
+	reportPicturesCmd.Flags().IntVar(&flagMetaPictureMaxDimension, "max-dimension", pkg.DefaultMaxDimension, "Pictures exceeding this width or height are reported as oversized, 0 to disable")
+	reportPicturesCmd.Flags().Int64Var(&flagMetaPictureMaxBytes, "max-bytes", defaultMaxPictureBytes, "Pictures exceeding this size in bytes are reported as oversized, 0 to disable")
+	reportPicturesCmd.Flags().BoolVarP(&flagMetaJsonOutput, "json", "j", false, "Encode result to JSON instead of printing a human-friendly table")
+}
+
+func runReportPictures(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := strings.TrimSuffix(pkg.GetExpandedFile(args[0]), "/")
+	result, err := reportPictures(target, flagMetaPictureMaxDimension, flagMetaPictureMaxBytes)
+	if err != nil {
+		return err
+	}
+
+	return result.Run()
+}
+
+// pictureUsage sums up the embedded pictures of a set of files
+type pictureUsage struct {
+	Files    int
+	Pictures int
+	Bytes    int64
+}
+
+type oversizedPicture struct {
+	File       string
+	Index      int
+	Resolution string
+	Bytes      int64
+	// EstimatedBytes is the estimated size after scaling the picture down to the max dimension
+	EstimatedBytes int64
+}
+
+type duplicatePictures struct {
+	File string
+	// Indexes of the pictures that are identical to an earlier picture of the same file
+	Indexes []int
+	Bytes   int64
+}
+
+type pictureReport struct {
+	// dir - usage
+	Albums     map[string]*pictureUsage
+	Total      pictureUsage
+	Oversized  []oversizedPicture
+	Duplicates []duplicatePictures
+}
+
+// Savings returns the estimated number of bytes that can be saved by scaling down oversized pictures and
+// removing duplicated ones.
+func (r pictureReport) Savings() int64 {
+	var savings int64
+	for _, picture := range r.Oversized {
+		savings += picture.Bytes - picture.EstimatedBytes
+	}
+	for _, duplicates := range r.Duplicates {
+		savings += duplicates.Bytes
+	}
+	return savings
+}
+
+func reportPictures(target string, maxDimension int, maxBytes int64) (*internal.GenericResult[pictureReport], error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &internal.GenericResult[pictureReport]{
+		Operation: "pictures-report",
+		Execute:   picturesReportAction,
+		Data: pictureReport{
+			Albums:     make(map[string]*pictureUsage),
+			Oversized:  make([]oversizedPicture, 0),
+			Duplicates: make([]duplicatePictures, 0),
+		},
+	}
+
+	// a limit of 0 is disabled
+	limits := pkg.CoverRequirements{MaxDimension: maxDimension, MaxBytes: maxBytes}
+
+	var files []string
+	if !info.IsDir() {
+		files = []string{target}
+	} else {
+		err = filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || !strings.HasSuffix(strings.ToLower(info.Name()), ".flac") {
+				return nil
+			}
+
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+
+	var errs error
+	for _, file := range files {
+		images, err := internal.GetFlacImages(file)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		dir := filepath.Dir(file)
+		usage, found := result.Data.Albums[dir]
+		if !found {
+			usage = &pictureUsage{}
+			result.Data.Albums[dir] = usage
+		}
+		usage.Files++
+		result.Data.Total.Files++
+
+		duplicates, err := findDuplicatePictures(file, images)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		}
+
+		duplicateBytes := int64(0)
+		for i, img := range images {
+			usage.Pictures++
+			usage.Bytes += img.Bytes()
+			result.Data.Total.Pictures++
+			result.Data.Total.Bytes += img.Bytes()
+
+			// duplicates are removed entirely, so there is nothing to save by scaling them down
+			if duplicates[i+1] {
+				duplicateBytes += img.Bytes()
+				continue
+			}
+
+			width, height := img.Dimensions()
+			if len(limits.Check(width, height, img.Bytes(), "")) > 0 {
+				estimated := pkg.EstimateOptimizedSize(img.Bytes(), width, height, maxDimension)
+				result.Data.Oversized = append(result.Data.Oversized, oversizedPicture{
+					File:           file,
+					Index:          i + 1,
+					Resolution:     fmt.Sprintf("%dx%d", width, height),
+					Bytes:          img.Bytes(),
+					EstimatedBytes: estimated,
+				})
+			}
+		}
+
+		if len(duplicates) > 0 {
+			var indexes []int
+			for index := range duplicates {
+				indexes = append(indexes, index)
+			}
+			sort.Ints(indexes)
+
+			result.Data.Duplicates = append(result.Data.Duplicates, duplicatePictures{
+				File:    file,
+				Indexes: indexes,
+				Bytes:   duplicateBytes,
+			})
+		}
+	}
+
+	return result, errs
+}
+
+// findDuplicatePictures returns the (1-based) indexes of the pictures of a file that are identical to an
+// earlier picture of the same file. Only pictures of equal size are read and compared.
+func findDuplicatePictures(file string, images []internal.FlacImage) (map[int]bool, error) {
+	bySize := make(map[int64][]int)
+	for i, img := range images {
+		bySize[img.Bytes()] = append(bySize[img.Bytes()], i)
+	}
+
+	duplicates := make(map[int]bool)
+	var errs error
+	for _, indexes := range bySize {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, index := range indexes {
+			data, err := internal.ReadPicture(file, images[index].BlockNumber)
+			if err != nil {
+				errs = multierr.Append(errs, err)
+				continue
+			}
+
+			hash := dataHash(data)
+			if seen[hash] {
+				duplicates[index+1] = true
+			}
+			seen[hash] = true
+		}
+	}
+
+	return duplicates, errs
+}
+
+func picturesReportAction(action *internal.GenericResult[pictureReport]) error {
+	report := action.Data
+
+	if flagMetaJsonOutput {
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+		return nil
+	}
+
+	dirs := make([]string, 0, len(report.Albums))
+	for dir := range report.Albums {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return report.Albums[dirs[i]].Bytes > report.Albums[dirs[j]].Bytes
+	})
+
+	var data [][]string
+	for _, dir := range dirs {
+		usage := report.Albums[dir]
+		data = append(data, []string{dir, strconv.Itoa(usage.Files), strconv.Itoa(usage.Pictures), pkg.HumanSize(usage.Bytes)})
+	}
+	tui.PrintTable("Embedded Pictures", []string{"Directory", "Files", "Pictures", "Size"}, data, tui.TableOpts{})
+
+	if len(report.Oversized) > 0 {
+		data = nil
+		for _, picture := range report.Oversized {
+			data = append(data, []string{
+				picture.File,
+				strconv.Itoa(picture.Index),
+				picture.Resolution,
+				pkg.HumanSize(picture.Bytes),
+				pkg.HumanSize(picture.EstimatedBytes),
+			})
+		}
+		tui.PrintTable("Oversized Pictures", []string{"File", "#", "Resolution", "Size", "Estimated Size"}, data, tui.TableOpts{})
+	}
+
+	if len(report.Duplicates) > 0 {
+		data = nil
+		for _, duplicates := range report.Duplicates {
+			var indexes []string
+			for _, index := range duplicates.Indexes {
+				indexes = append(indexes, strconv.Itoa(index))
+			}
+			data = append(data, []string{duplicates.File, strings.Join(indexes, ", "), pkg.HumanSize(duplicates.Bytes)})
+		}
+		tui.PrintTable("Duplicate Pictures", []string{"File", "#", "Size"}, data, tui.TableOpts{})
+	}
+
+	numberStyle := lipgloss.NewStyle().Bold(true)
+	categoryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("%s %s\n",
+		numberStyle.Render(pkg.HumanSize(report.Total.Bytes)),
+		categoryStyle.Render(fmt.Sprintf("in %d pictures embedded in %d files", report.Total.Pictures, report.Total.Files)),
+	)
+	fmt.Printf("%s %s\n",
+		numberStyle.Render(pkg.HumanSize(report.Savings())),
+		categoryStyle.Render(fmt.Sprintf("estimated savings from resizing %d and removing %d duplicated pictures", len(report.Oversized), duplicateCount(report.Duplicates))),
+	)
+
+	return nil
+}
+
+func duplicateCount(duplicates []duplicatePictures) int {
+	count := 0
+	for _, d := range duplicates {
+		count += len(d.Indexes)
+	}
+	return count
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"github.com/soerenschneider/flac-mate/pkg"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+var syncPictureCmd = &cobra.Command{
+	Use: "picture-sync [target]",
+	Aliases: []string{
+		"pic-sync",
+		"sync-picture",
+		"sync-pics",
+	},
+	Short: "Embeds the folder cover of each album into all of its tracks whose front cover differs",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSyncPicture,
+}
+
+func init() {
+	metadataCmd.AddCommand(syncPictureCmd)
+	syncPictureCmd.Flags().BoolVarP(&flagMetaPictureOptimize, "optimize", "o", false, "Scale down and recompress the folder cover to a baseline JPEG before embedding, the original file is left untouched")
+	syncPictureCmd.Flags().IntVar(&flagMetaPictureMaxDimension, "max-dimension", pkg.DefaultMaxDimension, "Maximum width and height of optimized pictures, 0 keeps the original size")
+	syncPictureCmd.Flags().IntVar(&flagMetaPictureQuality, "quality", pkg.DefaultJpegQuality, "JPEG quality of optimized pictures (1-100)")
+}
+
+func runSyncPicture(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := strings.TrimSuffix(pkg.GetExpandedFile(args[0]), "/")
+
+	var opts *pkg.OptimizeOpts
+	if flagMetaPictureOptimize {
+		opts = &pkg.OptimizeOpts{
+			MaxDimension: flagMetaPictureMaxDimension,
+			Quality:      flagMetaPictureQuality,
+		}
+	}
+
+	result, err := planAlbumCovers(target, opts, pictureAddModeReplaceType)
+	if result != nil {
+		defer result.Data.Cleanup()
+	}
+	if err != nil {
+		return err
+	}
+
+	return result.Run()
+}
+
+// albumCover is the cover of an album and the tracks it needs to be embedded into
+type albumCover struct {
+	// Cover is the folder cover of the album
+	Cover string
+	// Embedded is the file that gets embedded, either the folder cover or an optimized copy of it
+	Embedded string
+	Files    []string
+}
+
+type albumCoversOp struct {
+	Albums map[string]albumCover
+	// Skipped holds the albums that could not be planned, dir - error
+	Skipped     map[string]string
+	Description string
+	Mode        string
+}
+
+// Cleanup removes optimized copies of the folder covers
+func (op albumCoversOp) Cleanup() {
+	for _, album := range op.Albums {
+		if album.Embedded != album.Cover {
+			_ = os.Remove(album.Embedded)
+		}
+	}
+}
+
+// planAlbumCovers walks the tree below target and determines for each album folder which of its
+// tracks do not embed the album's own folder cover yet. Albums with unreadable tracks or covers are
+// skipped and reported, the other albums are still planned.
+func planAlbumCovers(target string, opts *pkg.OptimizeOpts, mode string) (*internal.GenericResult[albumCoversOp], error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", target)
+	}
+
+	var files []string
+	err = filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(strings.ToLower(info.Name()), ".flac") {
+			return nil
+		}
+
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filesByDir := make(map[string][]string)
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		filesByDir[dir] = append(filesByDir[dir], file)
+	}
+
+	result := &internal.GenericResult[albumCoversOp]{
+		Operation: "album-covers",
+		Data: albumCoversOp{
+			Albums:  make(map[string]albumCover),
+			Skipped: make(map[string]string),
+			Mode:    mode,
+		},
+		Execute: albumCoversAction,
+	}
+
+	for dir, dirFiles := range filesByDir {
+		album, err := planAlbumCover(dir, dirFiles, opts)
+		if err != nil {
+			result.Data.Skipped[dir] = err.Error()
+			continue
+		}
+
+		if album.Embedded != "" {
+			result.Data.Albums[dir] = album
+		}
+	}
+
+	return result, nil
+}
+
+func planAlbumCover(dir string, files []string, opts *pkg.OptimizeOpts) (albumCover, error) {
+	folderCover, found, err := fetchFolderCover(dir)
+	if err != nil {
+		return albumCover{}, err
+	}
+	if !found {
+		tui.Warn(fmt.Sprintf("no folder cover found in %q", dir))
+		return albumCover{}, nil
+	}
+
+	album := albumCover{
+		Cover:    folderCover.Path,
+		Embedded: folderCover.Path,
+	}
+
+	wantedHash := folderCover.Hash
+	if opts != nil {
+		optimized, err := pkg.WriteOptimizedImage(folderCover.Path, "", *opts)
+		if err != nil {
+			return albumCover{}, err
+		}
+		album.Embedded = optimized
+
+		wantedHash, err = fileHash(optimized)
+		if err != nil {
+			_ = os.Remove(optimized)
+			return albumCover{}, err
+		}
+	}
+
+	var errs error
+	for _, file := range files {
+		embedded, found, err := fetchEmbeddedFrontCover(file, false)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		if !found || embedded.Hash != wantedHash {
+			album.Files = append(album.Files, file)
+		}
+	}
+	sort.Strings(album.Files)
+
+	// an album is only embedded into if all of its tracks could be read
+	if errs != nil || len(album.Files) == 0 {
+		if album.Embedded != album.Cover {
+			_ = os.Remove(album.Embedded)
+		}
+		return albumCover{}, errs
+	}
+
+	return album, nil
+}
+
+func albumCoversAction(action *internal.GenericResult[albumCoversOp]) error {
+	var skipped error
+	if len(action.Data.Skipped) > 0 {
+		var tableData [][]string
+		for _, dir := range slices.Sorted(maps.Keys(action.Data.Skipped)) {
+			tableData = append(tableData, []string{dir, action.Data.Skipped[dir]})
+		}
+		tui.PrintTable("Skipped Albums", []string{"Album", "Error"}, tableData, tui.TableOpts{})
+		skipped = fmt.Errorf("skipped %d albums that could not be read", len(action.Data.Skipped))
+	}
+
+	if len(action.Data.Albums) == 0 {
+		if skipped != nil {
+			return skipped
+		}
+		successStyle := lipgloss.NewStyle().Bold(true)
+		fmt.Println(successStyle.Render("✓ All tracks embed their folder cover!"))
+		return nil
+	}
+
+	dirs := make([]string, 0, len(action.Data.Albums))
+	for dir := range action.Data.Albums {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var tableData [][]string
+	for _, dir := range dirs {
+		album := action.Data.Albums[dir]
+		for _, file := range album.Files {
+			tableData = append(tableData, []string{file, filepath.Base(album.Cover)})
+		}
+	}
+
+	tui.PrintTable("Affected Files", []string{"File", "Cover"}, tableData, tui.TableOpts{})
+
+	proceed, err := tui.Confirm("Proceed with embedding covers?")
+	if err != nil {
+		return err
+	}
+
+	if !proceed {
+		return skipped
+	}
+
+	for _, dir := range dirs {
+		album := action.Data.Albums[dir]
+		op := addImageOp{
+			ImageFile:   album.Embedded,
+			PictureType: internal.PictureTypeFront,
+			Description: action.Data.Description,
+			Mode:        action.Data.Mode,
+		}
+
+		for _, file := range album.Files {
+			if err := embedPicture(file, op); err != nil {
+				return err
+			}
+		}
+	}
+
+	return skipped
+}
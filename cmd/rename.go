@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"maps"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/soerenschneider/flac-mate/internal"
@@ -12,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -22,8 +24,14 @@ import (
 var renameCmd = &cobra.Command{
 	Use:   "rename",
 	Short: "Rename FLAC files and directories based on metadata",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runRenamer,
+	Long: `Rename FLAC files and directories based on metadata.
+
+Besides the short notation of tags (e.g. %a for ARTIST), schemes support placeholders for technical properties:
+  %bd bit depth, %sr sample rate in kHz, %ch channels, %co codec, %du duration, %as album size
+
+For directories, %du is the playing time of the whole album. Example: "%a - %d - %b [%co %bd-%sr]"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRenamer,
 }
 
 const (
@@ -35,10 +43,14 @@ var (
 	// Unwanted characters for filenames
 	unwantedCharsRegex = regexp.MustCompile(`[/\\!?%$*|"'<>]`)
 
-	flagRenameScheme    string
-	flagRenameDirScheme string
-	flagRenameDryrun    bool
-	flagRenameCoverName string
+	flagRenameScheme      string
+	flagRenameDirScheme   string
+	flagRenameDryrun      bool
+	flagRenameCoverName   string
+	flagRenameInteractive bool
+
+	flagRenameOrganizeArtwork bool
+	flagRenameArtworkDir      string
 )
 
 func init() {
@@ -48,6 +60,12 @@ func init() {
 	renameCmd.Flags().StringVarP(&flagRenameDirScheme, "directory-scheme", "d", defaultRenameDirScheme, "Directory naming scheme")
 	renameCmd.Flags().BoolVarP(&flagRenameDryrun, "dry-run", "n", false, "Dry run mode")
 	renameCmd.Flags().StringVarP(&flagRenameCoverName, "cover-name", "c", "cover", "Cover image name")
+	renameCmd.Flags().BoolVarP(&flagRenameInteractive, "interactive", "i", false, "Interactively select the renames to apply and choose the cover if it is ambiguous")
+	renameCmd.Flags().BoolVarP(&flagRenameOrganizeArtwork, "organize-artwork", "a", false, "Rename further artwork (back, disc, booklet) consistently")
+	renameCmd.Flags().StringVar(&flagRenameArtworkDir, "artwork-dir", "", "Subfolder to move further artwork to, e.g. \"Artwork\" or \"Scans\" (implies --organize-artwork)")
+	renameCmd.Flags().StringVarP(&flagResolveStrategy, "resolve", "r", resolveNone, fmt.Sprintf("Strategy to resolve multi-valued album tags %v", resolveStrategies))
+	renameCmd.Flags().BoolVarP(&flagResolveWriteBack, "write-back", "w", false, "Write resolved values back to the files that differ")
+	addPreviewFlags(renameCmd)
 }
 
 // runRenamer is the main command handler
@@ -66,6 +84,14 @@ func runRenamer(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := validateResolveStrategy(flagResolveStrategy); err != nil {
+		return err
+	}
+
+	if err := validatePreviewFlags(cmd); err != nil {
+		return err
+	}
+
 	if info, err := os.Stat(target); err != nil || !info.IsDir() {
 		return err
 	}
@@ -90,6 +116,7 @@ func runRenamer(cmd *cobra.Command, args []string) error {
 
 	// Process directories from deepest to shallowest
 	var errs error
+	var actions []*renameAction
 	for i := len(dirs) - 1; i >= 0; i-- {
 		dirname := dirs[i]
 
@@ -107,25 +134,24 @@ func runRenamer(cmd *cobra.Command, args []string) error {
 		}
 
 		action := workDir(dirname, filenames, fileScheme, dirScheme, flagRenameCoverName)
-		if action != nil && action.Actionable() {
-			if err := action.CarryOut(flagRenameDryrun); err != nil {
-				errs = multierr.Append(errs, err)
-			}
-		} else {
-			if action.EncounteredErrors() {
-				//return action.Errors
-				errs = multierr.Append(errs, action.Errors)
-			} else {
-				successStyle := lipgloss.NewStyle().Bold(true)
-				fmt.Println(successStyle.Render("✓ No renaming needed!"))
-			}
-		}
-
 		if action.EncounteredErrors() {
-			errs = multierr.Append(errs, err)
+			errs = multierr.Append(errs, action.Errors)
+		}
+		if action.Actionable() {
+			actions = append(actions, action)
 		}
 	}
 
+	if len(actions) == 0 {
+		successStyle := lipgloss.NewStyle().Bold(true)
+		fmt.Println(successStyle.Render("✓ No renaming needed!"))
+		return errs
+	}
+
+	if err := carryOutRenames(actions, flagRenameDryrun, flagRenameInteractive); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+
 	return errs
 }
 
@@ -135,6 +161,8 @@ type renameAction struct {
 	FileActions    map[string]string
 	DirAction      [2]string // [old, new]
 	ImageAction    [2]string // [old, new]
+	ArtworkActions map[string]string
+	TagActions     map[string]map[string]string
 	Errors         error
 	hasDirAction   bool
 	hasImageAction bool
@@ -143,8 +171,10 @@ type renameAction struct {
 // NewAction creates a new Action instance
 func NewAction(directory string) *renameAction {
 	return &renameAction{
-		Dir:         directory,
-		FileActions: make(map[string]string),
+		Dir:            directory,
+		FileActions:    make(map[string]string),
+		TagActions:     make(map[string]map[string]string),
+		ArtworkActions: make(map[string]string),
 	}
 }
 
@@ -161,6 +191,16 @@ func (a *renameAction) AddFileAction(oldFilepath, newFilepath string) {
 	a.FileActions[oldFilepath] = newFilepath
 }
 
+// AddTagAction adds an action to write tags to a file before it is renamed
+func (a *renameAction) AddTagAction(filepath string, tags map[string]string) {
+	a.TagActions[filepath] = tags
+}
+
+// AddArtworkAction adds an action to rename or move further artwork
+func (a *renameAction) AddArtworkAction(oldFilepath, newFilepath string) {
+	a.ArtworkActions[oldFilepath] = newFilepath
+}
+
 // SetDirAction adds an action to rename a directory
 func (a *renameAction) SetDirAction(oldFilepath, newFilepath string) {
 	a.DirAction = [2]string{oldFilepath, newFilepath}
@@ -174,40 +214,160 @@ func (a *renameAction) SetImageAction(oldFilepath, newFilepath string) {
 }
 
 func (a *renameAction) Actionable() bool {
-	return len(a.FileActions) > 0 || a.hasDirAction
+	return len(a.FileActions) > 0 || len(a.TagActions) > 0 || len(a.ArtworkActions) > 0 || a.hasDirAction || a.hasImageAction
 }
 
 func (a *renameAction) EncounteredErrors() bool {
 	return a.Errors != nil
 }
 
-// CarryOut actually performs the operations
-func (a *renameAction) CarryOut(dryrun bool) error {
-	var data [][]string
-	headers := []string{"Old", "New"}
+const (
+	renameKindFile      = "file"
+	renameKindCover     = "cover"
+	renameKindArtwork   = "artwork"
+	renameKindDirectory = "directory"
+)
 
-	// Rename files
-	for oldPath, newPath := range a.FileActions {
-		data = append(data, []string{oldPath, newPath})
+// renameMove is a single move operation of a rename action
+type renameMove struct {
+	Kind string
+	Old  string
+	New  string
+}
+
+func (m renameMove) String() string {
+	return fmt.Sprintf("%-9s %s → %s", m.Kind, m.Old, m.New)
+}
+
+// Moves returns all moves of the action in the order they need to be carried out: files and images
+// first, the directory that contains them last.
+func (a *renameAction) Moves() []renameMove {
+	var moves []renameMove
+
+	oldPaths := make([]string, 0, len(a.FileActions))
+	for oldPath := range a.FileActions {
+		oldPaths = append(oldPaths, oldPath)
+	}
+	sort.Strings(oldPaths)
+
+	for _, oldPath := range oldPaths {
+		moves = append(moves, renameMove{Kind: renameKindFile, Old: oldPath, New: a.FileActions[oldPath]})
 	}
 
-	// Rename image
 	if a.hasImageAction {
-		data = append(data, []string{a.ImageAction[0], a.ImageAction[1]})
+		moves = append(moves, renameMove{Kind: renameKindCover, Old: a.ImageAction[0], New: a.ImageAction[1]})
+	}
+
+	artworkPaths := make([]string, 0, len(a.ArtworkActions))
+	for oldPath := range a.ArtworkActions {
+		artworkPaths = append(artworkPaths, oldPath)
+	}
+	sort.Strings(artworkPaths)
+
+	for _, oldPath := range artworkPaths {
+		moves = append(moves, renameMove{Kind: renameKindArtwork, Old: oldPath, New: a.ArtworkActions[oldPath]})
 	}
 
-	// Rename directory
 	if a.hasDirAction {
-		data = append(data, []string{a.DirAction[0], a.DirAction[1]})
+		moves = append(moves, renameMove{Kind: renameKindDirectory, Old: a.DirAction[0], New: a.DirAction[1]})
+	}
+
+	return moves
+}
+
+// carryOutRenames presents the moves and tag writes of all actions at once and performs them after a single
+// confirmation. Actions are expected to be ordered from the deepest to the shallowest directory.
+func carryOutRenames(actions []*renameAction, dryrun, interactive bool) error {
+	var moves []renameMove
+	// the directory of the action each move and tag write belongs to
+	var moveDirs []string
+	tagDirs := make(map[string]string)
+	tagActions := make(map[string]map[string]string)
+	for _, action := range actions {
+		for _, move := range action.Moves() {
+			moves = append(moves, move)
+			moveDirs = append(moveDirs, action.Dir)
+		}
+		for file, tags := range action.TagActions {
+			tagActions[file] = tags
+			tagDirs[file] = action.Dir
+		}
+	}
+
+	if interactive {
+		tagFiles := slices.Sorted(maps.Keys(tagActions))
+		labels := make([]string, 0, len(tagFiles)+len(moves))
+		for _, file := range tagFiles {
+			labels = append(labels, fmt.Sprintf("%-9s %s: %s", "tags", file, remediation{SetTags: tagActions[file]}.Change()))
+		}
+		for _, move := range moves {
+			labels = append(labels, move.String())
+		}
+
+		selected, err := tui.SelectItems("Select renames to apply", labels, true)
+		if err != nil {
+			return err
+		}
+
+		for i, file := range tagFiles {
+			if !selected[i] {
+				delete(tagActions, file)
+			}
+		}
+
+		var accepted []renameMove
+		var acceptedDirs []string
+		for i, move := range moves {
+			if selected[len(tagFiles)+i] {
+				accepted = append(accepted, move)
+				acceptedDirs = append(acceptedDirs, moveDirs[i])
+			}
+		}
+		moves = accepted
+		moveDirs = acceptedDirs
 	}
 
-	tui.PrintTable("Move", headers, data, tui.TableOpts{})
+	if len(moves) == 0 && len(tagActions) == 0 {
+		tui.Info("No renames selected")
+		return nil
+	}
+
+	// deselected moves may leave files in place that selected moves would overwrite
+	if err := checkMoveTargets(moves); err != nil {
+		return err
+	}
+
+	if len(tagActions) > 0 {
+		var data [][]string
+		for file, tags := range tagActions {
+			for tag, value := range tags {
+				data = append(data, []string{file, tag, value})
+			}
+		}
+		tui.PrintTable("Write Tags", []string{"File", "Tag", "Value"}, data, tui.TableOpts{})
+	}
+
+	if len(moves) > 0 {
+		var data [][]string
+		for _, move := range moves {
+			data = append(data, []string{move.Kind, move.Old, move.New})
+		}
+		tui.PrintTable("Move", []string{"Type", "Old", "New"}, data, tui.TableOpts{})
+	}
 
 	if dryrun {
 		return nil
 	}
 
-	proceed, err := tui.Confirm("Proceed?")
+	dirs := make(map[string]bool)
+	for _, dir := range moveDirs {
+		dirs[dir] = true
+	}
+	for file := range tagActions {
+		dirs[tagDirs[file]] = true
+	}
+
+	proceed, err := tui.Confirm(fmt.Sprintf("Proceed with %d renames and %d tag writes in %d directories?", len(moves), len(tagActions), len(dirs)))
 	if err != nil {
 		return err
 	}
@@ -216,13 +376,14 @@ func (a *renameAction) CarryOut(dryrun bool) error {
 		return nil
 	}
 
-	for _, tuples := range data {
-		if err := os.Rename(tuples[0], tuples[1]); err != nil {
+	// tags need to be written before the files are moved away
+	for file, tags := range tagActions {
+		if err := internal.SetMetadata(file, tags, true); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return moveFiles(moves)
 }
 
 // unwrapKeys processes the scheming arguments
@@ -234,9 +395,22 @@ func unwrapKeys(scheme string, directory bool) (string, error) {
 		}
 	}
 
+	mappings := make(map[string]string, len(internal.MAPPINGS)+len(internal.SyntheticMappings))
+	maps.Copy(mappings, internal.MAPPINGS)
+	maps.Copy(mappings, internal.SyntheticMappings)
+
+	// Replace longer short tags first, otherwise %b would clobber %ba and %bd
+	shorts := slices.Collect(maps.Keys(mappings))
+	sort.Slice(shorts, func(i, j int) bool {
+		if len(shorts[i]) != len(shorts[j]) {
+			return len(shorts[i]) > len(shorts[j])
+		}
+		return shorts[i] < shorts[j]
+	})
+
 	// Replace short tags with long format
-	for short, long := range internal.MAPPINGS {
-		scheme = strings.ReplaceAll(scheme, "%"+short, "%("+long+")s")
+	for _, short := range shorts {
+		scheme = strings.ReplaceAll(scheme, "%"+short, "%("+mappings[short]+")s")
 	}
 
 	return scheme, nil
@@ -264,6 +438,32 @@ func hasSufficientMetadata(metadata map[string]string, scheme string) bool {
 	return true
 }
 
+// schemeTags returns all tags that are referenced in an unwrapped scheme
+func schemeTags(scheme string) []string {
+	re := regexp.MustCompile(`\((\w+)\)`)
+	matches := re.FindAllStringSubmatch(scheme, -1)
+
+	var tags []string
+	for _, match := range matches {
+		if len(match) > 1 && !slices.Contains(tags, match[1]) {
+			tags = append(tags, match[1])
+		}
+	}
+	return tags
+}
+
+// usesTechnicalTags checks whether an unwrapped scheme references synthetic technical tags
+func usesTechnicalTags(scheme string) bool {
+	for _, tag := range schemeTags(scheme) {
+		for _, technicalTag := range internal.SyntheticMappings {
+			if tag == technicalTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // renameFile renames a file based on scheme and metadata
 func renameFile(scheme, dirname, filename string, metadata map[string]string) (string, string, bool) {
 	path := filepath.Join(dirname, filename)
@@ -364,9 +564,8 @@ func canRenameDirectory(metadata map[string]map[string]bool, scheme string) (boo
 	return true, nil
 }
 
-func renameCover(dirname string, images []string, coverName string) (string, string, bool) {
-	selectedImage, err := pkg.GetMainCover(dirname, images)
-	if selectedImage == "" || err != nil {
+func renameCover(dirname string, selectedImage string, coverName string) (string, string, bool) {
+	if selectedImage == "" {
 		return "", "", false
 	}
 
@@ -381,6 +580,35 @@ func renameCover(dirname string, images []string, coverName string) (string, str
 	return oldFilepath, newFilepath, true
 }
 
+// organizeArtwork returns the moves needed to consistently name all artwork except the main cover
+// and optionally move it into a subfolder.
+// returns old path - new path
+func organizeArtwork(dirname, cover string, images, documents []string, artworkDir string) map[string]string {
+	var artwork []string
+	for _, image := range images {
+		if image != cover {
+			artwork = append(artwork, image)
+		}
+	}
+	artwork = append(artwork, documents...)
+
+	targetDir := dirname
+	if artworkDir != "" {
+		targetDir = filepath.Join(dirname, artworkDir)
+	}
+
+	moves := make(map[string]string)
+	for oldName, newName := range pkg.ArtworkNames(dirname, artwork) {
+		oldPath := filepath.Join(dirname, oldName)
+		newPath := filepath.Join(targetDir, newName)
+		if oldPath != newPath {
+			moves[oldPath] = newPath
+		}
+	}
+
+	return moves
+}
+
 func isImage(filename string) bool {
 	imageExtensions := map[string]bool{
 		".jpg":  true,
@@ -388,6 +616,7 @@ func isImage(filename string) bool {
 		".png":  true,
 		".gif":  true,
 		".bmp":  true,
+		".tif":  true,
 		".tiff": true,
 		".webp": true,
 	}
@@ -403,10 +632,15 @@ func isImage(filename string) bool {
 
 func workDir(dirname string, filenames []string, fileScheme, dirScheme, coverName string) *renameAction {
 	albumMetadata := make(map[string]map[string]bool)
+	collectedMetadata := make(map[string]map[string]string)
 	var collectedImages []string
-	var fileMetadata map[string]string
+	var collectedDocuments []string
+	var flacFiles []string
+	var streamInfos []internal.StreamInfo
 	dirContainsMusic := false
 
+	needsStreamInfo := usesTechnicalTags(fileScheme) || usesTechnicalTags(dirScheme)
+
 	action := NewAction(dirname)
 
 	// Sort filenames for consistent processing
@@ -417,31 +651,138 @@ func workDir(dirname string, filenames []string, fileScheme, dirScheme, coverNam
 			dirContainsMusic = true
 			filepath := filepath.Join(dirname, filename)
 
-			var err error
-			fileMetadata, err = internal.FetchMetadata(filepath, nil, false)
+			fileMetadata, err := internal.FetchMetadata(filepath, nil, false)
 			if err != nil {
 				action.AddError(internal.ErrIncompleteMetadata)
 				continue
 			}
 
-			if !hasSufficientMetadata(fileMetadata, fileScheme) {
-				action.AddError(fmt.Errorf("no sufficient metadata for %s", filename))
-			} else {
-				if oldPath, newPath, shouldRename := renameFile(fileScheme, dirname, filename, fileMetadata); shouldRename {
-					action.AddFileAction(oldPath, newPath)
+			if needsStreamInfo {
+				streamInfo, err := internal.FetchStreamInfo(filepath)
+				if err != nil {
+					action.AddError(err)
+					continue
 				}
-				appendMetadata(albumMetadata, fileMetadata)
+				streamInfos = append(streamInfos, streamInfo)
+				maps.Copy(fileMetadata, internal.TrackTechnicalMetadata(streamInfo))
 			}
-		} else if isImage(filename) {
+
+			flacFiles = append(flacFiles, filename)
+			collectedMetadata[filepath] = fileMetadata
+		} else if isImage(filepath.Join(dirname, filename)) {
 			collectedImages = append(collectedImages, filename)
+		} else if strings.HasSuffix(strings.ToLower(filename), ".pdf") {
+			collectedDocuments = append(collectedDocuments, filename)
+		}
+	}
+
+	var albumTechnicalMetadata map[string]string
+	if needsStreamInfo && len(streamInfos) > 0 {
+		var err error
+		albumTechnicalMetadata, err = internal.AlbumTechnicalMetadata(streamInfos)
+		if err != nil && usesTechnicalTags(dirScheme) {
+			action.AddError(fmt.Errorf("%w: %s", err, filepath.Base(dirname)))
+		}
+
+		// the album size is the same for every track
+		if size, found := albumTechnicalMetadata[internal.SyntheticAlbumSizeTag]; found {
+			for _, fileMetadata := range collectedMetadata {
+				fileMetadata[internal.SyntheticAlbumSizeTag] = size
+			}
+		}
+	}
+
+	var sufficientFiles []string
+	sufficientMetadata := make(map[string]map[string]string)
+	for _, filename := range flacFiles {
+		path := filepath.Join(dirname, filename)
+		if !hasSufficientMetadata(collectedMetadata[path], fileScheme) {
+			action.AddError(fmt.Errorf("no sufficient metadata for %s", filename))
+			continue
+		}
+		sufficientFiles = append(sufficientFiles, filename)
+		sufficientMetadata[path] = collectedMetadata[path]
+	}
+	flacFiles = sufficientFiles
+	collectedMetadata = sufficientMetadata
+
+	// Resolve tags that differ across the album's files, synthetic tags are not stored in the files and the
+	// technical ones are handled separately
+	var albumTags []string
+	for _, tag := range append(schemeTags(dirScheme), flagMetaUniformTags...) {
+		if !strings.HasPrefix(tag, "_") && !slices.Contains(albumTags, tag) {
+			albumTags = append(albumTags, tag)
+		}
+	}
+	tagValues := collectTagValues(collectedMetadata, albumTags)[dirname]
+	resolved, err := resolveTags(dirname, tagValues, flagResolveStrategy)
+	action.AddError(err)
+
+	if flagResolveWriteBack {
+		for file, tags := range outlierFixes(tagValues, resolved) {
+			maps.DeleteFunc(tags, func(tag, _ string) bool {
+				return strings.HasPrefix(tag, "_")
+			})
+			if len(tags) == 0 {
+				continue
+			}
+			action.AddTagAction(file, tags)
+			for tag, value := range tags {
+				collectedMetadata[file][tag] = value
+			}
+		}
+	}
+
+	for _, filename := range flacFiles {
+		fileMetadata := collectedMetadata[filepath.Join(dirname, filename)]
+		if oldPath, newPath, shouldRename := renameFile(fileScheme, dirname, filename, fileMetadata); shouldRename {
+			action.AddFileAction(oldPath, newPath)
+		}
+		appendMetadata(albumMetadata, fileMetadata)
+	}
+
+	for tag, value := range resolved {
+		albumMetadata[tag] = map[string]bool{value: true}
+	}
+
+	// technical properties of the album replace the ones of the individual tracks
+	if needsStreamInfo {
+		for _, tag := range internal.SyntheticMappings {
+			albumMetadata[tag] = map[string]bool{}
+			if value, found := albumTechnicalMetadata[tag]; found {
+				albumMetadata[tag][value] = true
+			}
 		}
 	}
 
 	if dirContainsMusic {
-		// Handle cover image renaming
-		if len(collectedImages) > 0 {
-			if oldPath, newPath, shouldRename := renameCover(dirname, collectedImages, coverName); shouldRename {
-				action.SetImageAction(oldPath, newPath)
+		// Handle cover image renaming and further artwork. The moves are checked together as an artwork file
+		// may take the name of another one that is renamed itself, they are dropped together on conflicts.
+		cover, err := chooseFolderCover(dirname, collectedImages, flagRenameInteractive, false)
+		if err != nil {
+			action.AddError(err)
+		}
+
+		var imageMoves []renameMove
+		if oldPath, newPath, shouldRename := renameCover(dirname, cover, coverName); shouldRename {
+			imageMoves = append(imageMoves, renameMove{Kind: renameKindCover, Old: oldPath, New: newPath})
+		}
+		if flagRenameOrganizeArtwork || flagRenameArtworkDir != "" {
+			artwork := organizeArtwork(dirname, cover, collectedImages, collectedDocuments, flagRenameArtworkDir)
+			for _, oldPath := range slices.Sorted(maps.Keys(artwork)) {
+				imageMoves = append(imageMoves, renameMove{Kind: renameKindArtwork, Old: oldPath, New: artwork[oldPath]})
+			}
+		}
+
+		if err := checkMoveTargets(imageMoves); err != nil {
+			action.AddError(fmt.Errorf("not renaming the artwork of %s: %w", filepath.Base(dirname), err))
+		} else {
+			for _, move := range imageMoves {
+				if move.Kind == renameKindCover {
+					action.SetImageAction(move.Old, move.New)
+				} else {
+					action.AddArtworkAction(move.Old, move.New)
+				}
 			}
 		}
 
@@ -449,7 +790,7 @@ func workDir(dirname string, filenames []string, fileScheme, dirScheme, coverNam
 		canRenameDir, err := canRenameDirectory(albumMetadata, dirScheme)
 		if err != nil {
 			action.AddError(fmt.Errorf("%w: %s", err, filepath.Base(dirname)))
-		} else if canRenameDir && fileMetadata != nil {
+		} else if canRenameDir && len(flacFiles) > 0 {
 			// Convert albumMetadata to single-value metadata for directory naming
 			singleMetadata := make(map[string]string)
 			for key, valueSet := range albumMetadata {
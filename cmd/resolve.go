@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/soerenschneider/flac-mate/internal"
+	"github.com/soerenschneider/flac-mate/internal/tui"
+	"go.uber.org/multierr"
+)
+
+const (
+	resolveNone        = "none"
+	resolveMajority    = "majority"
+	resolveFirst       = "first"
+	resolveInteractive = "interactive"
+)
+
+var resolveStrategies = []string{resolveNone, resolveMajority, resolveFirst, resolveInteractive}
+
+func validateResolveStrategy(strategy string) error {
+	if !slices.Contains(resolveStrategies, strategy) {
+		return fmt.Errorf("unknown resolution strategy %q, valid strategies are %v", strategy, resolveStrategies)
+	}
+	return nil
+}
+
+// collectTagValues groups the values of the given tags by directory. The values of each directory
+// are ordered by file name, so the first value belongs to the first track.
+// returns dir - { tag: [file=value, ...] }
+func collectTagValues(collectedMetadata map[string]map[string]string, tags []string) map[string]map[string][]internal.TagValue {
+	files := make([]string, 0, len(collectedMetadata))
+	for file := range collectedMetadata {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	dirValues := make(map[string]map[string][]internal.TagValue)
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if _, exists := dirValues[dir]; !exists {
+			dirValues[dir] = make(map[string][]internal.TagValue)
+		}
+
+		for _, tag := range tags {
+			value, found := collectedMetadata[file][tag]
+			if found {
+				dirValues[dir][tag] = append(dirValues[dir][tag], internal.TagValue{File: file, Value: value})
+			}
+		}
+	}
+
+	return dirValues
+}
+
+// resolveTags picks a single value for each multi-valued tag of a directory according to the strategy.
+// Tags that can not be resolved are returned as error.
+func resolveTags(dir string, tagValues map[string][]internal.TagValue, strategy string) (map[string]string, error) {
+	tags := make([]string, 0, len(tagValues))
+	for tag := range tagValues {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	resolved := make(map[string]string)
+	var errs error
+	for _, tag := range tags {
+		values := tagValues[tag]
+		if len(internal.DistinctValues(values)) < 2 {
+			continue
+		}
+
+		value, err := resolveTagValue(dir, tag, values, strategy)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%w: %s (%s)", err, tag, filepath.Base(dir)))
+			continue
+		}
+		resolved[tag] = value
+	}
+
+	return resolved, errs
+}
+
+func resolveTagValue(dir, tag string, values []internal.TagValue, strategy string) (string, error) {
+	switch strategy {
+	case resolveMajority:
+		return internal.ResolveMajority(values)
+	case resolveFirst:
+		return internal.ResolveFirst(values)
+	case resolveInteractive:
+		title := fmt.Sprintf("Choose %s for %s", tag, dir)
+		return tui.SelectInput(title, internal.DistinctValues(values)), nil
+	default:
+		return "", internal.ErrMultiValuedTags
+	}
+}
+
+// outlierFixes returns the writes needed to make all files carry the resolved values.
+// returns file - { tag: value }
+func outlierFixes(tagValues map[string][]internal.TagValue, resolved map[string]string) map[string]map[string]string {
+	fixes := make(map[string]map[string]string)
+	for tag, value := range resolved {
+		for _, file := range internal.Outliers(tagValues[tag], value) {
+			if _, found := fixes[file]; !found {
+				fixes[file] = make(map[string]string)
+			}
+			fixes[file][tag] = value
+		}
+	}
+	return fixes
+}
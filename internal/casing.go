@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type CaseStyle string
+
+const (
+	// CaseStyleTitle capitalizes all words except small words such as articles and short prepositions
+	CaseStyleTitle CaseStyle = "title"
+	// CaseStyleSentence only capitalizes the first word of each phrase
+	CaseStyleSentence CaseStyle = "sentence"
+)
+
+var CaseStyles = []CaseStyle{CaseStyleTitle, CaseStyleSentence}
+
+// ParseCaseStyle parses the name of a case style.
+func ParseCaseStyle(style string) (CaseStyle, error) {
+	parsed := CaseStyle(strings.ToLower(strings.TrimSpace(style)))
+	if !slices.Contains(CaseStyles, parsed) {
+		return "", fmt.Errorf("unknown case style %q, valid styles are %v", style, CaseStyles)
+	}
+	return parsed, nil
+}
+
+// CaseLanguage holds the casing rules of a language.
+type CaseLanguage struct {
+	// SmallWords are lower-cased within a phrase in title case
+	SmallWords []string
+	// Capitalized words are always capitalized, e.g. the English "I"
+	Capitalized []string
+	// NumeralLikeWords are ordinary words that are not upper-cased as Roman numerals, e.g. the Spanish "vi"
+	NumeralLikeWords []string
+	// PreserveWordCase keeps the case of the words following the first word in sentence case, for
+	// languages that capitalize nouns
+	PreserveWordCase bool
+}
+
+// DefaultCaseLanguage is used for values of unknown language
+const DefaultCaseLanguage = "en"
+
+// CaseLanguages maps ISO 639-1 codes to their casing rules
+var CaseLanguages = map[string]CaseLanguage{
+	"en": {
+		SmallWords: []string{"a", "an", "the", "and", "but", "or", "nor", "for", "so", "yet", "as", "at", "by", "in", "of",
+			"off", "on", "per", "to", "up", "via", "vs", "vs.", "feat.", "ft.", "n'", "o'", "'n'"},
+		Capitalized: []string{"i"},
+	},
+	"de": {
+		SmallWords: []string{"der", "die", "das", "den", "dem", "des", "ein", "eine", "einer", "eines", "einem", "einen",
+			"und", "oder", "aber", "von", "vom", "zu", "zum", "zur", "mit", "im", "in", "am", "an", "auf", "aus", "bei",
+			"für", "über", "unter", "nach", "vor", "um", "bis", "durch", "ohne", "gegen"},
+		PreserveWordCase: true,
+	},
+	"fr": {
+		SmallWords: []string{"le", "la", "les", "l'", "un", "une", "des", "du", "de", "d'", "et", "ou", "à", "au", "aux",
+			"en", "par", "pour", "sur", "dans", "avec", "sans"},
+	},
+	"es": {
+		SmallWords: []string{"el", "la", "los", "las", "un", "una", "unos", "unas", "y", "e", "o", "u", "de", "del", "a",
+			"al", "en", "con", "por", "para", "sin", "sobre"},
+		NumeralLikeWords: []string{"vi"},
+	},
+	"it": {
+		SmallWords: []string{"il", "lo", "la", "i", "gli", "le", "l'", "un", "uno", "una", "e", "o", "di", "del", "della",
+			"a", "al", "alla", "da", "in", "con", "su", "per", "tra", "fra"},
+		NumeralLikeWords: []string{"vi"},
+	},
+	"nl": {
+		SmallWords: []string{"de", "het", "een", "en", "of", "van", "in", "op", "te", "aan", "met", "voor", "bij", "uit",
+			"naar", "om", "'t"},
+	},
+	"pt": {
+		SmallWords: []string{"o", "a", "os", "as", "um", "uma", "e", "ou", "de", "do", "da", "dos", "das", "em", "no",
+			"na", "nos", "nas", "por", "com", "sem", "para"},
+		NumeralLikeWords: []string{"vi"},
+	},
+}
+
+// languageCodes maps ISO 639-2 codes and names used in LANGUAGE tags to ISO 639-1 codes
+var languageCodes = map[string]string{
+	"eng": "en", "english": "en",
+	"deu": "de", "ger": "de", "german": "de", "deutsch": "de",
+	"fra": "fr", "fre": "fr", "french": "fr", "français": "fr",
+	"spa": "es", "spanish": "es", "español": "es",
+	"ita": "it", "italian": "it", "italiano": "it",
+	"nld": "nl", "dut": "nl", "dutch": "nl", "nederlands": "nl",
+	"por": "pt", "portuguese": "pt", "português": "pt",
+}
+
+// LanguageCode returns the ISO 639-1 code of a LANGUAGE tag value such as "de", "ger" or "German", or an
+// empty string if there are no casing rules for the language.
+func LanguageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, found := languageCodes[language]; found {
+		return code
+	}
+	// regional variants such as "en-US" or "pt_BR"
+	if code, _, found := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-"); found {
+		language = code
+	}
+	if _, found := CaseLanguages[language]; found {
+		return language
+	}
+	return ""
+}
+
+var (
+	// matches Roman numerals from 2 to 39, single letters are ambiguous
+	romanNumeralRegex  = regexp.MustCompile(`(?i)^(?:x{0,3})(?:ix|iv|v?i{0,3})$`)
+	leadingPunctRegex  = regexp.MustCompile(`^[(\[{"'¿¡“‘«]+`)
+	trailingPunctRegex = regexp.MustCompile(`[)\]}"',.!?:;”’»]+$`)
+)
+
+// Casing transforms the case of tag values.
+type Casing struct {
+	Style CaseStyle
+	// Language is the ISO 639-1 code of the casing rules, see CaseLanguages
+	Language string
+	// Exceptions are written exactly as given wherever they occur as a word, regardless of their case,
+	// e.g. "AC/DC", "iPhone" or "DJ"
+	Exceptions []string
+}
+
+// Apply transforms the case of a value. In mixed-case values, acronyms such as "USA" and words with inner
+// capitals such as "McCartney" are kept. Roman numerals and exceptions are protected in any case, except
+// for words of the language that look like numerals.
+func (c Casing) Apply(value string) string {
+	language, found := CaseLanguages[c.Language]
+	if !found {
+		language = CaseLanguages[DefaultCaseLanguage]
+	}
+
+	exceptions := make(map[string]string, len(c.Exceptions))
+	for _, exception := range c.Exceptions {
+		exceptions[strings.ToLower(exception)] = exception
+	}
+
+	shouting := !strings.ContainsFunc(value, unicode.IsLower)
+	whispering := !strings.ContainsFunc(value, unicode.IsUpper)
+	uniform := shouting || whispering
+
+	words := strings.Split(value, " ")
+	last := len(words) - 1
+	for last >= 0 && words[last] == "" {
+		last--
+	}
+
+	startOfPhrase := true
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+
+		prefix := leadingPunctRegex.FindString(word)
+		core := strings.TrimPrefix(word, prefix)
+		suffix := trailingPunctRegex.FindString(core)
+		core = strings.TrimSuffix(core, suffix)
+
+		first := startOfPhrase || strings.ContainsAny(prefix, "([{")
+		startOfPhrase = isPhraseSeparator(core) || strings.ContainsAny(suffix, ":!?")
+		if core == "" {
+			continue
+		}
+
+		if exception, found := lookupException(exceptions, core, suffix); found {
+			words[i] = prefix + exception
+			continue
+		}
+
+		lower := strings.ToLower(core)
+		var cased string
+		switch {
+		case romanNumeralRegex.MatchString(core) && len(core) > 1 && !slices.Contains(language.NumeralLikeWords, lower):
+			cased = strings.ToUpper(core)
+		case !uniform && isAcronym(core), !uniform && hasInnerCapital(core):
+			cased = core
+		case slices.Contains(language.Capitalized, lower):
+			cased = capitalize(lower)
+		case c.Style == CaseStyleTitle:
+			if !first && i != last && slices.Contains(language.SmallWords, lower) {
+				cased = lower
+			} else {
+				cased = capitalizeParts(lower)
+			}
+		case first:
+			cased = capitalize(lower)
+		case language.PreserveWordCase && !uniform:
+			cased = core
+		case language.PreserveWordCase && shouting && !slices.Contains(language.SmallWords, lower):
+			// nouns cannot be told apart from other words, but are more likely
+			cased = capitalize(lower)
+		default:
+			cased = lower
+		}
+
+		words[i] = prefix + cased + suffix
+	}
+
+	return strings.Join(words, " ")
+}
+
+// lookupException returns the exception of a word, trying the word with its trailing punctuation first
+// to match exceptions such as "feat."
+func lookupException(exceptions map[string]string, core, suffix string) (string, bool) {
+	if exception, found := exceptions[strings.ToLower(core+suffix)]; found {
+		return exception, true
+	}
+	if exception, found := exceptions[strings.ToLower(core)]; found {
+		return exception + suffix, true
+	}
+	return "", false
+}
+
+func isPhraseSeparator(word string) bool {
+	return word == "-" || word == "–" || word == "—" || word == "/"
+}
+
+// isAcronym returns whether a word consists of at least two letters that are all upper case
+func isAcronym(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters > 1
+}
+
+// hasInnerCapital returns whether an upper case letter follows a lower case letter, e.g. "McCartney"
+func hasInnerCapital(word string) bool {
+	seenLower := false
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			seenLower = true
+		} else if unicode.IsUpper(r) && seenLower {
+			return true
+		}
+	}
+	return false
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	runes := []rune(word)
+	for i, r := range runes {
+		if unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+			break
+		}
+		if unicode.IsDigit(r) {
+			break
+		}
+	}
+	return string(runes)
+}
+
+// capitalizeParts capitalizes every part of a hyphenated or slashed word, e.g. "hip-hop" becomes "Hip-Hop"
+func capitalizeParts(word string) string {
+	var builder strings.Builder
+	start := 0
+	for i, r := range word {
+		if r == '-' || r == '/' {
+			builder.WriteString(capitalize(word[start:i]))
+			builder.WriteRune(r)
+			start = i + 1
+		}
+	}
+	builder.WriteString(capitalize(word[start:]))
+	return builder.String()
+}
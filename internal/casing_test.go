@@ -0,0 +1,59 @@
+package internal
+
+import "testing"
+
+func TestCasingApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		casing Casing
+		value  string
+		want   string
+	}{
+		{name: "title from upper", casing: Casing{Style: CaseStyleTitle}, value: "THE LONG AND WINDING ROAD", want: "The Long and Winding Road"},
+		{name: "title from lower", casing: Casing{Style: CaseStyleTitle}, value: "a day in the life", want: "A Day in the Life"},
+		{name: "title last small word", casing: Casing{Style: CaseStyleTitle}, value: "something to believe in", want: "Something to Believe In"},
+		{name: "title phrase", casing: Casing{Style: CaseStyleTitle}, value: "symphony no. 5: the of", want: "Symphony No. 5: The Of"},
+		{name: "title parenthesis", casing: Casing{Style: CaseStyleTitle}, value: "song (of the year) - a remix", want: "Song (Of the Year) - A Remix"},
+		{name: "title hyphen", casing: Casing{Style: CaseStyleTitle}, value: "hip-hop is dead", want: "Hip-Hop Is Dead"},
+		{name: "title apostrophe", casing: Casing{Style: CaseStyleTitle}, value: "don't stop me now", want: "Don't Stop Me Now"},
+		{name: "acronyms kept", casing: Casing{Style: CaseStyleTitle}, value: "born in the USA", want: "Born in the USA"},
+		{name: "inner capitals kept", casing: Casing{Style: CaseStyleTitle}, value: "songs by paul McCartney", want: "Songs by Paul McCartney"},
+		{name: "roman numerals", casing: Casing{Style: CaseStyleTitle}, value: "LED ZEPPELIN IV", want: "Led Zeppelin IV"},
+		{name: "roman numerals lower", casing: Casing{Style: CaseStyleSentence}, value: "part ii", want: "Part II"},
+		{name: "spanish word like a numeral", casing: Casing{Style: CaseStyleSentence, Language: "es"}, value: "TE VI AYER", want: "Te vi ayer"},
+		{name: "italian word like a numeral", casing: Casing{Style: CaseStyleTitle, Language: "it"}, value: "vi amo", want: "Vi Amo"},
+		{name: "roman numerals in other languages", casing: Casing{Style: CaseStyleTitle, Language: "es"}, value: "sinfonía ix", want: "Sinfonía IX"},
+		{name: "exceptions", casing: Casing{Style: CaseStyleTitle, Exceptions: []string{"AC/DC", "feat."}}, value: "HIGHWAY TO HELL FEAT. AC/DC!", want: "Highway to Hell feat. AC/DC!"},
+		{name: "sentence", casing: Casing{Style: CaseStyleSentence}, value: "The Dark Side Of The Moon", want: "The dark side of the moon"},
+		{name: "sentence pronoun", casing: Casing{Style: CaseStyleSentence}, value: "WHAT I LIKE", want: "What I like"},
+		{name: "german sentence keeps nouns", casing: Casing{Style: CaseStyleSentence, Language: "de"}, value: "das Lied von der Glocke", want: "Das Lied von der Glocke"},
+		{name: "german sentence from upper", casing: Casing{Style: CaseStyleSentence, Language: "de"}, value: "DAS LIED VON DER GLOCKE", want: "Das Lied von der Glocke"},
+		{name: "french title", casing: Casing{Style: CaseStyleTitle, Language: "fr"}, value: "LA VIE EN ROSE", want: "La Vie en Rose"},
+		{name: "unicode", casing: Casing{Style: CaseStyleTitle, Language: "de"}, value: "ÜBER DEN WOLKEN", want: "Über den Wolken"},
+		{name: "spaces kept", casing: Casing{Style: CaseStyleTitle}, value: "one  two ", want: "One  Two "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.casing.Apply(tt.value); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageCode(t *testing.T) {
+	tests := map[string]string{
+		"en":      "en",
+		"ger":     "de",
+		"German":  "de",
+		"pt_BR":   "pt",
+		"en-US":   "en",
+		"klingon": "",
+		"":        "",
+	}
+	for language, want := range tests {
+		if got := LanguageCode(language); got != want {
+			t.Errorf("LanguageCode(%q) = %q, want %q", language, got, want)
+		}
+	}
+}
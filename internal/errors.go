@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrIncompleteMetadata = errors.New("incomplete metadata")
 	ErrMultiValuedTags    = errors.New("found multi-valued tags")
+	ErrNoMajority         = errors.New("no majority value")
 )
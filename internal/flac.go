@@ -26,6 +26,7 @@ const (
 	TagDiscNumber  = "DISCNUMBER"
 	TagDiscsTotal  = "DISCTOTAL"
 	TagGenre       = "GENRE"
+	TagLanguage    = "LANGUAGE"
 	TagTitle       = "TITLE"
 	TagTrackNumber = "TRACKNUMBER"
 	TagTracksTotal = "TRACKTOTAL"
@@ -65,6 +66,7 @@ var MAPPINGS = map[string]string{
 }
 
 type FlacImage struct {
+	BlockNumber int
 	Type        string
 	MIMEType    string
 	Description string
@@ -272,10 +274,144 @@ func SetMetadata(filepath string, data map[string]string, force bool) error {
 	return nil
 }
 
-// SetPicture deletes all pictures and then writes the specified picture for a given file.
+// FetchTagValues fetches all values of a tag that may occur multiple times, such as GENRE, see
+// FetchAllTagValues.
+func FetchTagValues(filepath string, tag string) ([]string, error) {
+	values, err := FetchAllTagValues(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	return values[strings.ToUpper(tag)], nil
+}
+
+// FetchAllTagValues fetches all values of all tags. Unlike FetchMetadata, tags that occur multiple times keep
+// all of their values and the values are returned as they are, including surrounding whitespace and line
+// breaks of multi-line values such as LYRICS.
+// returns tag - values, tag names are upper-cased
+func FetchAllTagValues(filepath string) (map[string][]string, error) {
+	_, err := os.Stat(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	// --export-tags-to prints the comments line by line, values spanning several lines can not be told
+	// apart from further comments. --list prints the comments numbered, which delimits them.
+	cmd := exec.Command("metaflac", "--list", "--block-type=VORBIS_COMMENT", "--no-utf8-convert", filepath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			stderrOutput := strings.TrimSpace(stderr.String())
+			if stderrOutput != "" {
+				return nil, fmt.Errorf("metaflac list failed (exit code %d): %s", exitError.ExitCode(), stderrOutput)
+			}
+			return nil, fmt.Errorf("metaflac list failed with exit code %d", exitError.ExitCode())
+		}
+		return nil, fmt.Errorf("metaflac list failed to execute: %v", err)
+	}
+
+	values, err := parseVorbisComments(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the tags of %q: %w", filepath, err)
+	}
+	return values, nil
+}
+
+// parseVorbisComments returns the non-empty values of all tags from the output of metaflac --list for the
+// VORBIS_COMMENT block. Each comment starts with a "comment[n]: " line and runs up to the next one, so
+// values may span several lines.
+// returns tag - values, tag names are upper-cased
+func parseVorbisComments(output string) (map[string][]string, error) {
+	values := make(map[string][]string)
+
+	_, rest, found := strings.Cut(output, "\n  comments: ")
+	if !found {
+		return values, nil
+	}
+	countLine, rest, _ := strings.Cut(rest, "\n")
+	count, err := strconv.Atoi(strings.TrimSpace(countLine))
+	if err != nil {
+		return nil, fmt.Errorf("invalid number of comments %q", countLine)
+	}
+	// only the first block is read, files must not have several of them
+	rest, _, _ = strings.Cut(rest, "\nMETADATA block #")
+
+	for i := range count {
+		marker := fmt.Sprintf("    comment[%d]: ", i)
+		if !strings.HasPrefix(rest, marker) {
+			return nil, fmt.Errorf("missing comment %d of %d", i+1, count)
+		}
+		rest = rest[len(marker):]
+
+		var comment string
+		if i == count-1 {
+			comment = strings.TrimSuffix(rest, "\n")
+		} else {
+			index := strings.Index(rest, fmt.Sprintf("\n    comment[%d]: ", i+1))
+			if index < 0 {
+				return nil, fmt.Errorf("missing comment %d of %d", i+2, count)
+			}
+			comment, rest = rest[:index], rest[index+1:]
+		}
+
+		name, value, found := strings.Cut(comment, "=")
+		if !found || value == "" {
+			continue
+		}
+		name = strings.ToUpper(name)
+		values[name] = append(values[name], value)
+	}
+
+	return values, nil
+}
+
+// SetTagValues replaces all values of a tag with the given values, writing one tag per value.
+func SetTagValues(filepath string, tag string, values []string) error {
+	_, err := os.Stat(filepath)
+	if err != nil {
+		return err
+	}
+
+	tag = strings.ToUpper(tag)
+	// metaflac applies the operations in order
+	args := []string{fmt.Sprintf("--remove-tag=%s", tag)}
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			args = append(args, fmt.Sprintf("--set-tag=%s=%s", tag, value))
+		}
+	}
+	args = append(args, filepath)
+
+	cmd := exec.Command("metaflac", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			stderrOutput := strings.TrimSpace(stderr.String())
+			if stderrOutput != "" {
+				return fmt.Errorf("metaflac set failed (exit code %d): %s",
+					exitError.ExitCode(), stderrOutput)
+			}
+			return fmt.Errorf("metaflac set failed with exit code %d",
+				exitError.ExitCode())
+		}
+		return fmt.Errorf("metaflac set failed to execute: %v", err)
+	}
+
+	return nil
+}
+
+// SetPicture deletes all pictures and then writes the specified picture as front cover for a given file.
 func SetPicture(flacFilePath string, pictureFilePath string) error {
 	isValid, _, _, err := pkg.IsValidImage(pictureFilePath)
 	if !isValid {
+		if err == nil {
+			err = fmt.Errorf("%q is not a supported image", pictureFilePath)
+		}
 		return err
 	}
 
@@ -283,8 +419,40 @@ func SetPicture(flacFilePath string, pictureFilePath string) error {
 		return err
 	}
 
+	return AddPicture(flacFilePath, pictureFilePath, PictureTypeFront, "")
+}
+
+// AddPicture imports the specified picture with the given picture type and description into a given file,
+// keeping all pictures that are already embedded.
+func AddPicture(flacFilePath string, pictureFilePath string, pictureType int, description string) error {
+	isValid, _, _, err := pkg.IsValidImage(pictureFilePath)
+	if !isValid {
+		if err == nil {
+			err = fmt.Errorf("%q is not a supported image", pictureFilePath)
+		}
+		return err
+	}
+
+	if strings.Contains(description, "|") {
+		return errors.New("picture description must not contain '|'")
+	}
+
+	// players rarely display pictures other than JPEG and PNG, convert them before embedding
+	embeddable, converted, err := pkg.WriteEmbeddableImage(pictureFilePath, "")
+	if err != nil {
+		return err
+	}
+	if converted {
+		defer func() {
+			_ = os.Remove(embeddable)
+		}()
+		pictureFilePath = embeddable
+	}
+
+	// TYPE|MIME-TYPE|DESCRIPTION|WIDTHxHEIGHTxDEPTH/COLORS|FILE, empty fields are auto-detected
+	spec := fmt.Sprintf("%d||%s||%s", pictureType, description, pictureFilePath)
 	args := []string{
-		fmt.Sprintf("--import-picture-from=%s", pictureFilePath),
+		fmt.Sprintf("--import-picture-from=%s", spec),
 		flacFilePath,
 	}
 
@@ -307,6 +475,48 @@ func SetPicture(flacFilePath string, pictureFilePath string) error {
 	return nil
 }
 
+// DeletePictureBlocks deletes the picture blocks with the given block numbers from a given flac
+func DeletePictureBlocks(filepath string, images []FlacImage) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	_, err := os.Stat(filepath)
+	if err != nil {
+		return err
+	}
+
+	// all blocks are removed in a single run as block numbers shift after each removal
+	blockNumbers := make([]string, 0, len(images))
+	for _, img := range images {
+		blockNumbers = append(blockNumbers, strconv.Itoa(img.BlockNumber))
+	}
+
+	args := []string{
+		"--remove",
+		fmt.Sprintf("--block-number=%s", strings.Join(blockNumbers, ",")),
+		filepath,
+	}
+
+	cmd := exec.Command("metaflac", args...)
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			stderrOutput := strings.TrimSpace(stderr.String())
+			if stderrOutput != "" {
+				return fmt.Errorf("metaflac remove failed (exit code %d): %s", exitError.ExitCode(), stderrOutput)
+			}
+			return fmt.Errorf("metaflac remove failed with exit code %d", exitError.ExitCode())
+		}
+		return fmt.Errorf("metaflac remove failed to execute: %v", err)
+	}
+
+	return nil
+}
+
 // DeletePictures deletes all pictures from a given flac
 func DeletePictures(filepath string) error {
 	_, err := os.Stat(filepath)
@@ -339,6 +549,60 @@ func DeletePictures(filepath string) error {
 	return nil
 }
 
+// ExportPicture writes the picture stored in the given metadata block of a flac to dest.
+func ExportPicture(flacFilePath string, blockNumber int, dest string) error {
+	args := []string{
+		fmt.Sprintf("--block-number=%d", blockNumber),
+		fmt.Sprintf("--export-picture-to=%s", dest),
+		flacFilePath,
+	}
+
+	cmd := exec.Command("metaflac", args...)
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			stderrOutput := strings.TrimSpace(stderr.String())
+			if stderrOutput != "" {
+				return fmt.Errorf("metaflac export failed (exit code %d): %s", exitError.ExitCode(), stderrOutput)
+			}
+			return fmt.Errorf("metaflac export failed with exit code %d", exitError.ExitCode())
+		}
+		return fmt.Errorf("metaflac export failed to execute: %v", err)
+	}
+
+	return nil
+}
+
+// ReadPicture returns the data of the picture stored in the given metadata block of a flac.
+func ReadPicture(flacFilePath string, blockNumber int) ([]byte, error) {
+	args := []string{
+		fmt.Sprintf("--block-number=%d", blockNumber),
+		"--export-picture-to=-",
+		flacFilePath,
+	}
+
+	cmd := exec.Command("metaflac", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			stderrOutput := strings.TrimSpace(stderr.String())
+			if stderrOutput != "" {
+				return nil, fmt.Errorf("metaflac export failed (exit code %d): %s", exitError.ExitCode(), stderrOutput)
+			}
+			return nil, fmt.Errorf("metaflac export failed with exit code %d", exitError.ExitCode())
+		}
+		return nil, fmt.Errorf("metaflac export failed to execute: %v", err)
+	}
+
+	return output, nil
+}
+
 // ExpandTag expands the given tag from short notation to long notation.
 // Returns the string representation of the tag.
 func ExpandTag(tag string) (string, error) {
@@ -370,9 +634,12 @@ func GetFlacImages(filepath string) ([]FlacImage, error) {
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "METADATA block #") && current.MIMEType != "" {
-			images = append(images, current)
+		if strings.HasPrefix(line, "METADATA block #") {
+			if current.MIMEType != "" {
+				images = append(images, current)
+			}
 			current = FlacImage{}
+			current.BlockNumber, _ = strconv.Atoi(strings.TrimPrefix(line, "METADATA block #"))
 		}
 		switch {
 		case strings.HasPrefix(line, "type:"):
@@ -399,6 +666,35 @@ func GetFlacImages(filepath string) ([]FlacImage, error) {
 	return images, nil
 }
 
+// TypeID returns the picture type number of the image, e.g. 3 for "3 (Cover (front))".
+func (img FlacImage) TypeID() int {
+	number, _, _ := strings.Cut(img.Type, " ")
+	typeID, err := strconv.Atoi(number)
+	if err != nil {
+		return PictureTypeOther
+	}
+	return typeID
+}
+
+// Dimensions returns the width and height of the image, or zeros if they are unknown.
+func (img FlacImage) Dimensions() (int, int) {
+	width, errWidth := strconv.Atoi(img.Width)
+	height, errHeight := strconv.Atoi(img.Height)
+	if errWidth != nil || errHeight != nil {
+		return 0, 0
+	}
+	return width, height
+}
+
+// Bytes returns the size of the picture data in bytes, or zero if it is unknown.
+func (img FlacImage) Bytes() int64 {
+	size, err := strconv.ParseInt(img.Size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
 // String returns a human-readable string representation of the image metadata
 func (img FlacImage) String() string {
 	return fmt.Sprintf(
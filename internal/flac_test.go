@@ -48,3 +48,43 @@ func TestFetchMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVorbisComments(t *testing.T) {
+	output := "METADATA block #2\n" +
+		"  type: 4 (VORBIS_COMMENT)\n" +
+		"  is last: false\n" +
+		"  length: 200\n" +
+		"  vendor string: reference libFLAC 1.4.3 20230623\n" +
+		"  comments: 6\n" +
+		"    comment[0]: ARTIST=Björk \n" +
+		"    comment[1]: artist=Sigur Rós\n" +
+		"    comment[2]: LYRICS=First line\n" +
+		"TITLE=not a tag\n" +
+		"\n" +
+		"    comment[3]: COMMENT=\n" +
+		"    comment[4]: GENRE=Rock\n" +
+		"    comment[5]: DESCRIPTION=Last\n" +
+		"line\n"
+
+	want := map[string][]string{
+		TagArtist:     {"Björk ", "Sigur Rós"},
+		"LYRICS":      {"First line\nTITLE=not a tag\n"},
+		TagGenre:      {"Rock"},
+		"DESCRIPTION": {"Last\nline"},
+	}
+
+	got, err := parseVorbisComments(output)
+	if err != nil {
+		t.Fatalf("parseVorbisComments() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseVorbisComments() = %q, want %q", got, want)
+	}
+}
+
+func TestParseVorbisCommentsMissingComment(t *testing.T) {
+	output := "  vendor string: reference libFLAC\n  comments: 2\n    comment[0]: TITLE=One\n    comment[2]: TITLE=Two\n"
+	if _, err := parseVorbisComments(output); err == nil {
+		t.Errorf("parseVorbisComments() error = nil, want an error for a missing comment")
+	}
+}
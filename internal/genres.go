@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v3"
+)
+
+// CheckNonCanonicalGenre is the name of the check for genres that are not in the canonical list
+const CheckNonCanonicalGenre = "non-canonical-genre"
+
+// DefaultGenreSeparators split combined genres such as "Rap/Hip-Hop"
+var DefaultGenreSeparators = []string{"/", ";", ","}
+
+// GenreMap maps genre spellings to a canonical list of genres. Genres that only differ in case, spaces,
+// hyphens and punctuation, e.g. "Hip Hop", "hip-hop" and "HipHop", map to the same canonical genre
+// without being listed as aliases.
+//
+// Example:
+//
+//	canonical: [Hip-Hop, Rock, Electronic]
+//	aliases:
+//	  Hip-Hop: [Rap/Hip-Hop, Hip Hop/Rap]
+//	  Electronic: [Electronica, EDM]
+type GenreMap struct {
+	Canonical []string `yaml:"canonical" json:"canonical"`
+	// Aliases maps canonical genres to further spellings
+	Aliases map[string][]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	// Separators split combined genres, defaults to DefaultGenreSeparators
+	Separators []string `yaml:"separators,omitempty" json:"separators,omitempty"`
+
+	lookup map[string]string
+}
+
+// LoadGenreMap reads and validates a genre map from a YAML file.
+func LoadGenreMap(path string) (*GenreMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var genreMap GenreMap
+	if err := yaml.Unmarshal(data, &genreMap); err != nil {
+		return nil, fmt.Errorf("could not parse genre map %q: %w", path, err)
+	}
+
+	if err := genreMap.Compile(); err != nil {
+		return nil, fmt.Errorf("invalid genre map %q: %w", path, err)
+	}
+
+	return &genreMap, nil
+}
+
+// Compile validates the genre map and builds its lookup table.
+func (g *GenreMap) Compile() error {
+	if len(g.Canonical) == 0 {
+		return fmt.Errorf("no canonical genres")
+	}
+	if len(g.Separators) == 0 {
+		g.Separators = DefaultGenreSeparators
+	}
+
+	var errs error
+	g.lookup = make(map[string]string)
+	add := func(spelling, canonical string) {
+		key := genreKey(spelling)
+		if existing, found := g.lookup[key]; found && existing != canonical {
+			errs = multierr.Append(errs, fmt.Errorf("%q maps to both %q and %q", spelling, existing, canonical))
+			return
+		}
+		g.lookup[key] = canonical
+	}
+
+	for _, canonical := range g.Canonical {
+		add(canonical, canonical)
+	}
+	for canonical, aliases := range g.Aliases {
+		if !slices.Contains(g.Canonical, canonical) {
+			errs = multierr.Append(errs, fmt.Errorf("aliases of %q which is not a canonical genre", canonical))
+			continue
+		}
+		for _, alias := range aliases {
+			add(alias, canonical)
+		}
+	}
+
+	return errs
+}
+
+// genreKey reduces a genre to its lower-cased letters and digits, "&" and "+" are kept to tell "R&B" and "RnB"
+// apart from "RB"
+func genreKey(genre string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '&' || r == '+' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, genre)
+}
+
+// IsCanonical returns whether the genre is spelled exactly like a canonical genre.
+func (g *GenreMap) IsCanonical(genre string) bool {
+	return slices.Contains(g.Canonical, genre)
+}
+
+// Lookup returns the canonical genre of a spelling of a genre.
+func (g *GenreMap) Lookup(genre string) (string, bool) {
+	canonical, found := g.lookup[genreKey(genre)]
+	return canonical, found
+}
+
+// Canonicalize returns the canonical genres of a genre value. A value that is a known spelling as a whole
+// maps to its canonical genre. With split, other values are split into their parts if all of the parts are
+// known, e.g. "Rap/Hip-Hop" becomes "Rap" and "Hip-Hop". Otherwise, e.g. for "Singer/Songwriter", the
+// value is kept as it is and the bool is false.
+func (g *GenreMap) Canonicalize(genre string, split bool) ([]string, bool) {
+	genre = strings.TrimSpace(genre)
+	if canonical, found := g.Lookup(genre); found {
+		return []string{canonical}, true
+	}
+
+	if !split {
+		return []string{genre}, false
+	}
+
+	parts := []string{genre}
+	for _, separator := range g.Separators {
+		var split []string
+		for _, part := range parts {
+			split = append(split, strings.Split(part, separator)...)
+		}
+		parts = split
+	}
+
+	var genres []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		canonical, found := g.Lookup(part)
+		if !found {
+			return []string{genre}, false
+		}
+		if !slices.Contains(genres, canonical) {
+			genres = append(genres, canonical)
+		}
+	}
+
+	return genres, len(genres) > 0
+}
+
+// CanonicalizeAll returns the canonical genres of all values of a GENRE tag without duplicates. The bool
+// is false if any of the values is not known.
+func (g *GenreMap) CanonicalizeAll(values []string, split bool) ([]string, bool) {
+	var genres []string
+	known := true
+	for _, value := range values {
+		canonical, ok := g.Canonicalize(value, split)
+		known = known && ok
+		for _, genre := range canonical {
+			if !slices.Contains(genres, genre) {
+				genres = append(genres, genre)
+			}
+		}
+	}
+	return genres, known
+}
+
+// CheckGenres reports files with genres that are not spelled like a canonical genre.
+// genres is file - GENRE values
+func (g *GenreMap) CheckGenres(genres map[string][]string, split bool) []Finding {
+	var findings []Finding
+	for file, values := range genres {
+		for _, value := range values {
+			if g.IsCanonical(value) {
+				continue
+			}
+
+			message := fmt.Sprintf("%q is not a canonical genre", value)
+			if canonical, known := g.Canonicalize(value, split); known {
+				quoted := make([]string, 0, len(canonical))
+				for _, genre := range canonical {
+					quoted = append(quoted, strconv.Quote(genre))
+				}
+				message = fmt.Sprintf("%q should be %s", value, strings.Join(quoted, ", "))
+			}
+
+			findings = append(findings, Finding{
+				Rule:     CheckNonCanonicalGenre,
+				Severity: SeverityWarning,
+				Subject:  file,
+				Tag:      TagGenre,
+				Message:  message,
+			})
+		}
+	}
+
+	SortFindings(findings)
+	return findings
+}
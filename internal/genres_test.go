@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testGenreMap(t *testing.T) *GenreMap {
+	t.Helper()
+	genreMap := &GenreMap{
+		Canonical: []string{"Hip-Hop", "Rap", "Rock", "Electronic", "R&B"},
+		Aliases: map[string][]string{
+			"Hip-Hop":    {"Rap/Hip-Hop"},
+			"Electronic": {"Electronica", "EDM"},
+		},
+	}
+	if err := genreMap.Compile(); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return genreMap
+}
+
+func TestGenreMapCanonicalize(t *testing.T) {
+	genreMap := testGenreMap(t)
+
+	tests := []struct {
+		name      string
+		value     string
+		split     bool
+		want      []string
+		wantKnown bool
+	}{
+		{name: "canonical", value: "Hip-Hop", want: []string{"Hip-Hop"}, wantKnown: true},
+		{name: "spaces", value: "Hip Hop", want: []string{"Hip-Hop"}, wantKnown: true},
+		{name: "lower case", value: "hip-hop", want: []string{"Hip-Hop"}, wantKnown: true},
+		{name: "joined", value: "HipHop", want: []string{"Hip-Hop"}, wantKnown: true},
+		{name: "alias", value: "electronica", want: []string{"Electronic"}, wantKnown: true},
+		{name: "alias wins over split", value: "Rap/Hip-Hop", split: true, want: []string{"Hip-Hop"}, wantKnown: true},
+		{name: "ampersand kept", value: "r & b", want: []string{"R&B"}, wantKnown: true},
+		{name: "unknown", value: "Polka", want: []string{"Polka"}},
+		{name: "combined without split", value: "Rock/EDM", want: []string{"Rock/EDM"}},
+		{name: "combined", value: "Rock/EDM", split: true, want: []string{"Rock", "Electronic"}, wantKnown: true},
+		{name: "combined duplicates", value: "rock; Rock, hip hop", split: true, want: []string{"Rock", "Hip-Hop"}, wantKnown: true},
+		{name: "combined unknown", value: "Rock / Polka", split: true, want: []string{"Rock / Polka"}},
+		{name: "unknown with separator", value: "Singer/Songwriter", split: true, want: []string{"Singer/Songwriter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := genreMap.Canonicalize(tt.value, tt.split)
+			if !reflect.DeepEqual(got, tt.want) || known != tt.wantKnown {
+				t.Errorf("Canonicalize(%q, %v) = %q, %v, want %q, %v", tt.value, tt.split, got, known, tt.want, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestGenreMapCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		genreMap GenreMap
+		wantErr  bool
+	}{
+		{name: "valid", genreMap: GenreMap{Canonical: []string{"Rock"}, Aliases: map[string][]string{"Rock": {"Rock'n'Roll"}}}},
+		{name: "no canonical genres", genreMap: GenreMap{}, wantErr: true},
+		{name: "alias of unknown genre", genreMap: GenreMap{Canonical: []string{"Rock"}, Aliases: map[string][]string{"Pop": {"Pop Music"}}}, wantErr: true},
+		{name: "conflicting spellings", genreMap: GenreMap{Canonical: []string{"Hip-Hop", "Hip Hop"}}, wantErr: true},
+		{name: "conflicting alias", genreMap: GenreMap{Canonical: []string{"Rock", "Pop"}, Aliases: map[string][]string{"Pop": {"rock"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.genreMap.Compile(); (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenreMapCheckGenres(t *testing.T) {
+	genreMap := testGenreMap(t)
+
+	genres := map[string][]string{
+		"a.flac": {"Hip-Hop", "Rock"},
+		"b.flac": {"hip hop"},
+		"c.flac": {"Polka"},
+	}
+
+	findings := genreMap.CheckGenres(genres, false)
+	var subjects []string
+	for _, finding := range findings {
+		if finding.Rule != CheckNonCanonicalGenre || finding.Tag != TagGenre {
+			t.Errorf("unexpected finding %+v", finding)
+		}
+		subjects = append(subjects, finding.Subject)
+	}
+
+	if want := []string{"b.flac", "c.flac"}; !reflect.DeepEqual(subjects, want) {
+		t.Errorf("CheckGenres() subjects = %v, want %v", subjects, want)
+	}
+}
+
+func TestGenreMapCheckGenresSplit(t *testing.T) {
+	genreMap := testGenreMap(t)
+
+	genres := map[string][]string{
+		"a.flac": {"Rock/EDM"},
+		"b.flac": {"Singer/Songwriter"},
+	}
+
+	tests := []struct {
+		split bool
+		want  []string
+	}{
+		{split: false, want: []string{`"Rock/EDM" is not a canonical genre`, `"Singer/Songwriter" is not a canonical genre`}},
+		{split: true, want: []string{`"Rock/EDM" should be "Rock", "Electronic"`, `"Singer/Songwriter" is not a canonical genre`}},
+	}
+	for _, tt := range tests {
+		var messages []string
+		for _, finding := range genreMap.CheckGenres(genres, tt.split) {
+			messages = append(messages, finding.Message)
+		}
+		if !reflect.DeepEqual(messages, tt.want) {
+			t.Errorf("CheckGenres(split %v) = %q, want %q", tt.split, messages, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Names of the text hygiene checks
+const (
+	CheckSurroundingWhitespace = "surrounding-whitespace"
+	CheckDoubleSpaces          = "double-spaces"
+	CheckNotNFC                = "not-nfc"
+	CheckControlCharacters     = "control-characters"
+	CheckMojibake              = "mojibake"
+)
+
+var (
+	doubleSpacesRegex = regexp.MustCompile(`  +`)
+
+	// matches the first byte of a two to four byte UTF-8 sequence decoded as Windows-1252 or Latin-1,
+	// followed by a continuation byte decoded the same way, e.g. "Ã¶" for "ö"
+	mojibakeRegex = regexp.MustCompile("[Â-ô][\u0080-¿ŒœŠšŸŽžƒˆ˜–—‘-‚“-„†-•…‰‹›€™]")
+)
+
+// TextIssue is a hygiene problem of a tag value
+type TextIssue struct {
+	Check    string
+	Severity Severity
+	Message  string
+}
+
+// CheckText returns the hygiene problems of a tag value: surrounding whitespace, runs of spaces,
+// decomposed characters, control characters and UTF-8 that has been decoded as Latin-1 or Windows-1252.
+func CheckText(value string) []TextIssue {
+	var issues []TextIssue
+	if strings.TrimSpace(value) != value {
+		issues = append(issues, TextIssue{CheckSurroundingWhitespace, SeverityWarning, "leading or trailing whitespace"})
+	}
+	if doubleSpacesRegex.MatchString(value) {
+		issues = append(issues, TextIssue{CheckDoubleSpaces, SeverityInfo, "consecutive spaces"})
+	}
+	if !norm.NFC.IsNormalString(value) {
+		issues = append(issues, TextIssue{CheckNotNFC, SeverityWarning, "decomposed characters, not in Unicode normalization form C"})
+	}
+	if strings.IndexFunc(value, isStrippedControl) >= 0 {
+		issues = append(issues, TextIssue{CheckControlCharacters, SeverityWarning, "control characters"})
+	}
+	if repaired, ok := RepairMojibake(value); ok {
+		issues = append(issues, TextIssue{CheckMojibake, SeverityError, fmt.Sprintf("double-encoded UTF-8, probably %q", repaired)})
+	}
+	return issues
+}
+
+// CheckTextHygiene checks all tag values of all files, synthetic tags are skipped. The findings are
+// ordered by check, subject and tag.
+// values is file - { tag: values } as read by FetchAllTagValues
+func CheckTextHygiene(values map[string]map[string][]string) []Finding {
+	var findings []Finding
+	for file, tags := range values {
+		for tag, tagValues := range tags {
+			if strings.HasPrefix(tag, "_") {
+				continue
+			}
+			for _, value := range tagValues {
+				for _, issue := range CheckText(value) {
+					findings = append(findings, Finding{
+						Rule:     issue.Check,
+						Severity: issue.Severity,
+						Subject:  file,
+						Tag:      tag,
+						Message:  fmt.Sprintf("%s: %q", issue.Message, value),
+					})
+				}
+			}
+		}
+	}
+
+	SortFindings(findings)
+	return findings
+}
+
+// NormalizeText repairs double-encoded UTF-8, composes decomposed characters (NFC), strips control
+// characters except line breaks, collapses runs of spaces and trims surrounding whitespace.
+func NormalizeText(value string) string {
+	if repaired, ok := RepairMojibake(value); ok {
+		value = repaired
+	}
+
+	value = norm.NFC.String(value)
+	value = strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+		if isStrippedControl(r) {
+			return -1
+		}
+		return r
+	}, value)
+	value = doubleSpacesRegex.ReplaceAllString(value, " ")
+
+	return strings.TrimSpace(value)
+}
+
+// RepairMojibake reverts UTF-8 text that has been decoded as Windows-1252 or Latin-1 and encoded as UTF-8
+// again, e.g. "BjÃ¶rk" becomes "Björk". The bool is false if the value does not look double-encoded or
+// cannot be repaired.
+func RepairMojibake(value string) (string, bool) {
+	if !mojibakeRegex.MatchString(value) {
+		return "", false
+	}
+
+	repaired := value
+	// text may have been double-encoded more than once
+	for i := 0; i < 3 && mojibakeRegex.MatchString(repaired); i++ {
+		decoded, ok := reencode(repaired)
+		if !ok {
+			break
+		}
+		repaired = decoded
+	}
+
+	if repaired == value {
+		return "", false
+	}
+	return repaired, true
+}
+
+// reencode encodes the runes as Windows-1252, falling back to Latin-1 for the bytes Windows-1252 leaves
+// undefined, and decodes the bytes as UTF-8
+func reencode(value string) (string, bool) {
+	encoder := charmap.Windows1252.NewEncoder()
+	data := make([]byte, 0, len(value))
+	for _, r := range value {
+		if r >= 0x80 && r <= 0x9F {
+			data = append(data, byte(r))
+			continue
+		}
+		encoded, err := encoder.String(string(r))
+		if err != nil || len(encoded) != 1 {
+			return "", false
+		}
+		data = append(data, encoded[0])
+	}
+
+	if !utf8.Valid(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+func isStrippedControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\n'
+}
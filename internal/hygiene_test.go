@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "Björk"},
+		{value: "Line one\nLine two"},
+		{value: " Björk", want: []string{CheckSurroundingWhitespace}},
+		{value: "Sigur  Rós", want: []string{CheckDoubleSpaces}},
+		{value: "Björk", want: []string{CheckNotNFC}},
+		{value: "Bj\x00rk", want: []string{CheckControlCharacters}},
+		{value: "BjÃ¶rk", want: []string{CheckMojibake}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var got []string
+			for _, issue := range CheckText(tt.value) {
+				got = append(got, issue.Check)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CheckText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Björk", want: "Björk"},
+		{value: "  Björk \t", want: "Björk"},
+		{value: "Sigur  Rós", want: "Sigur Rós"},
+		{value: "Sigur\tRós", want: "Sigur Rós"},
+		{value: "Björk", want: "Björk"},
+		{value: "Bj\x00\x1brk", want: "Bjrk"},
+		{value: "Line one\nLine two", want: "Line one\nLine two"},
+		{value: "BjÃ¶rk", want: "Björk"},
+		{value: "â€œQuotedâ€\u009d", want: "“Quoted”"},
+		{value: "BjÃƒÂ¶rk", want: "Björk"},
+		{value: "CafÃ©", want: "Café"},
+		{value: "Motörhead Ãœber", want: "Motörhead Ãœber"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := NormalizeText(tt.value); got != tt.want {
+				t.Errorf("NormalizeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTextHygieneMultiValued(t *testing.T) {
+	values := map[string]map[string][]string{
+		"/a/1.flac": {
+			TagArtist:            {"Björk", "Sigur Rós "},
+			TagTitle:             {"Jóga"},
+			SyntheticFilePathTag: {" /a/1.flac"},
+		},
+	}
+
+	findings := CheckTextHygiene(values)
+	if len(findings) != 1 {
+		t.Fatalf("CheckTextHygiene() = %v, want a single finding", findings)
+	}
+	if got := findings[0]; got.Rule != CheckSurroundingWhitespace || got.Tag != TagArtist || !strings.Contains(got.Message, `"Sigur Rós "`) {
+		t.Errorf("CheckTextHygiene() = %+v, want surrounding whitespace of the second ARTIST value", got)
+	}
+}
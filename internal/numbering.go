@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Names of the track numbering checks
+const (
+	CheckDuplicateTrackNumber = "duplicate-track-number"
+	CheckTrackNumberGap       = "track-number-gap"
+	CheckTrackNumberExceeds   = "track-number-exceeds-total"
+	CheckIncompleteAlbum      = "incomplete-album"
+	CheckDuplicateTitle       = "duplicate-title"
+)
+
+// albumDisc holds the parsed track numbers of the files of a disc within an album directory
+type albumDisc struct {
+	disc   string
+	tracks map[int][]string
+	total  int
+}
+
+// CheckTrackNumbering checks that the track numbers of each album directory form a complete sequence
+// from 1 to TRACKTOTAL per disc and that no title occurs twice within an album. Tracks are grouped into
+// discs by DISCNUMBER, the total of a disc is the highest TRACKTOTAL (or "n/total" TRACKNUMBER) of its
+// tracks. The findings are ordered by check, subject and message.
+// metadata is file - { tag: value }
+func CheckTrackNumbering(metadata map[string]map[string]string) []Finding {
+	albums := make(map[string][]string)
+	for file := range metadata {
+		dir := filepath.Dir(file)
+		albums[dir] = append(albums[dir], file)
+	}
+
+	var findings []Finding
+	for dir, files := range albums {
+		sort.Strings(files)
+		for _, disc := range groupDiscs(files, metadata) {
+			findings = append(findings, disc.check(dir, metadata)...)
+		}
+		findings = append(findings, duplicateTitles(dir, files, metadata)...)
+	}
+
+	order := []string{CheckDuplicateTrackNumber, CheckTrackNumberExceeds, CheckTrackNumberGap, CheckIncompleteAlbum, CheckDuplicateTitle}
+	rank := func(check string) int {
+		for i, name := range order {
+			if name == check {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Rule != findings[j].Rule {
+			return rank(findings[i].Rule) < rank(findings[j].Rule)
+		}
+		if findings[i].Subject != findings[j].Subject {
+			return findings[i].Subject < findings[j].Subject
+		}
+		return findings[i].Message < findings[j].Message
+	})
+
+	return findings
+}
+
+func groupDiscs(files []string, metadata map[string]map[string]string) []*albumDisc {
+	discs := make(map[string]*albumDisc)
+	var order []string
+	for _, file := range files {
+		tags := metadata[file]
+		discNumber := strings.TrimSpace(tags[TagDiscNumber])
+		if disc, _, found := strings.Cut(discNumber, "/"); found {
+			discNumber = strings.TrimSpace(disc)
+		}
+		if parsed, err := strconv.Atoi(discNumber); err == nil {
+			discNumber = strconv.Itoa(parsed)
+		}
+
+		disc, found := discs[discNumber]
+		if !found {
+			disc = &albumDisc{disc: discNumber, tracks: make(map[int][]string)}
+			discs[discNumber] = disc
+			order = append(order, discNumber)
+		}
+
+		number, total, ok := NormalizeTrackNumber(tags[TagTrackNumber])
+		if !ok {
+			continue
+		}
+		parsed, _ := strconv.Atoi(number)
+		disc.tracks[parsed] = append(disc.tracks[parsed], file)
+
+		if total == "" {
+			total = strings.TrimSpace(tags[TagTracksTotal])
+		}
+		if parsedTotal, err := strconv.Atoi(total); err == nil && parsedTotal > disc.total {
+			disc.total = parsedTotal
+		}
+	}
+
+	sort.Strings(order)
+	result := make([]*albumDisc, 0, len(order))
+	for _, disc := range order {
+		result = append(result, discs[disc])
+	}
+	return result
+}
+
+func (d *albumDisc) label() string {
+	if d.disc == "" {
+		return ""
+	}
+	return fmt.Sprintf(" of disc %s", d.disc)
+}
+
+func (d *albumDisc) check(dir string, metadata map[string]map[string]string) []Finding {
+	if len(d.tracks) == 0 {
+		return nil
+	}
+
+	var findings []Finding
+	highest := 0
+	for number, files := range d.tracks {
+		if number > highest {
+			highest = number
+		}
+
+		if len(files) > 1 {
+			names := make([]string, 0, len(files))
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			findings = append(findings, Finding{
+				Rule:     CheckDuplicateTrackNumber,
+				Severity: SeverityError,
+				Subject:  dir,
+				Tag:      TagTrackNumber,
+				Message:  fmt.Sprintf("track %d%s is used by %s", number, d.label(), strings.Join(names, ", ")),
+			})
+		}
+
+		if d.total > 0 && number > d.total {
+			for _, file := range files {
+				findings = append(findings, Finding{
+					Rule:     CheckTrackNumberExceeds,
+					Severity: SeverityError,
+					Subject:  file,
+					Tag:      TagTrackNumber,
+					Message:  fmt.Sprintf("%q exceeds the total of %d tracks%s", metadata[file][TagTrackNumber], d.total, d.label()),
+				})
+			}
+		}
+	}
+
+	var gaps []string
+	for number := 1; number < highest; number++ {
+		if _, found := d.tracks[number]; !found {
+			gaps = append(gaps, strconv.Itoa(number))
+		}
+	}
+	if len(gaps) > 0 {
+		findings = append(findings, Finding{
+			Rule:     CheckTrackNumberGap,
+			Severity: SeverityWarning,
+			Subject:  dir,
+			Tag:      TagTrackNumber,
+			Message:  fmt.Sprintf("missing track numbers %s%s", strings.Join(gaps, ", "), d.label()),
+		})
+	}
+
+	present := 0
+	for number := range d.tracks {
+		if number >= 1 && number <= d.total {
+			present++
+		}
+	}
+	if d.total > 0 && present < d.total {
+		findings = append(findings, Finding{
+			Rule:     CheckIncompleteAlbum,
+			Severity: SeverityWarning,
+			Subject:  dir,
+			Tag:      TagTracksTotal,
+			Message:  fmt.Sprintf("%d of %d tracks present%s", present, d.total, d.label()),
+		})
+	}
+
+	return findings
+}
+
+// duplicateTitles reports titles that occur more than once within an album, ignoring case and
+// surrounding whitespace
+func duplicateTitles(dir string, files []string, metadata map[string]map[string]string) []Finding {
+	titles := make(map[string][]string)
+	var order []string
+	for _, file := range files {
+		title := strings.TrimSpace(metadata[file][TagTitle])
+		if title == "" {
+			continue
+		}
+		key := strings.ToLower(title)
+		if _, found := titles[key]; !found {
+			order = append(order, key)
+		}
+		titles[key] = append(titles[key], file)
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		files := titles[key]
+		if len(files) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			names = append(names, filepath.Base(file))
+		}
+		findings = append(findings, Finding{
+			Rule:     CheckDuplicateTitle,
+			Severity: SeverityWarning,
+			Subject:  dir,
+			Tag:      TagTitle,
+			Message:  fmt.Sprintf("%q is used by %s", strings.TrimSpace(metadata[files[0]][TagTitle]), strings.Join(names, ", ")),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckTrackNumbering(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]map[string]string
+		want     []Finding
+	}{
+		{
+			name: "complete",
+			metadata: map[string]map[string]string{
+				"/a/1.flac": {TagTrackNumber: "01", TagTracksTotal: "2", TagTitle: "One"},
+				"/a/2.flac": {TagTrackNumber: "02", TagTracksTotal: "2", TagTitle: "Two"},
+			},
+		},
+		{
+			name: "duplicate and exceeding",
+			metadata: map[string]map[string]string{
+				"/a/1.flac": {TagTrackNumber: "01", TagTracksTotal: "2"},
+				"/a/x.flac": {TagTrackNumber: "1", TagTracksTotal: "2"},
+				"/a/2.flac": {TagTrackNumber: "02", TagTracksTotal: "2"},
+				"/a/3.flac": {TagTrackNumber: "03", TagTracksTotal: "2"},
+			},
+			want: []Finding{
+				{Rule: CheckDuplicateTrackNumber, Severity: SeverityError, Subject: "/a", Tag: TagTrackNumber, Message: "track 1 is used by 1.flac, x.flac"},
+				{Rule: CheckTrackNumberExceeds, Severity: SeverityError, Subject: "/a/3.flac", Tag: TagTrackNumber, Message: `"03" exceeds the total of 2 tracks`},
+			},
+		},
+		{
+			name: "gap and incomplete",
+			metadata: map[string]map[string]string{
+				"/a/1.flac": {TagTrackNumber: "1/5"},
+				"/a/3.flac": {TagTrackNumber: "3/5"},
+			},
+			want: []Finding{
+				{Rule: CheckTrackNumberGap, Severity: SeverityWarning, Subject: "/a", Tag: TagTrackNumber, Message: "missing track numbers 2"},
+				{Rule: CheckIncompleteAlbum, Severity: SeverityWarning, Subject: "/a", Tag: TagTracksTotal, Message: "2 of 5 tracks present"},
+			},
+		},
+		{
+			name: "discs",
+			metadata: map[string]map[string]string{
+				"/a/1-1.flac": {TagDiscNumber: "1", TagTrackNumber: "01", TagTracksTotal: "1"},
+				"/a/2-1.flac": {TagDiscNumber: "02", TagTrackNumber: "01", TagTracksTotal: "2"},
+			},
+			want: []Finding{
+				{Rule: CheckIncompleteAlbum, Severity: SeverityWarning, Subject: "/a", Tag: TagTracksTotal, Message: "1 of 2 tracks present of disc 2"},
+			},
+		},
+		{
+			name: "duplicate titles",
+			metadata: map[string]map[string]string{
+				"/a/1.flac": {TagTrackNumber: "01", TagTitle: "Intro"},
+				"/a/2.flac": {TagTrackNumber: "02", TagTitle: "intro "},
+				"/b/1.flac": {TagTrackNumber: "01", TagTitle: "Intro"},
+			},
+			want: []Finding{
+				{Rule: CheckDuplicateTitle, Severity: SeverityWarning, Subject: "/a", Tag: TagTitle, Message: `"Intro" is used by 1.flac, 2.flac`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckTrackNumbering(tt.metadata)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckTrackNumbering() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
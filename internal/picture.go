@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Picture types as defined by the FLAC format (identical to the ID3v2 APIC frame)
+const (
+	PictureTypeOther          = 0
+	PictureTypeFileIcon       = 1
+	PictureTypeOtherFileIcon  = 2
+	PictureTypeFront          = 3
+	PictureTypeBack           = 4
+	PictureTypeLeaflet        = 5
+	PictureTypeMedia          = 6
+	PictureTypeLeadArtist     = 7
+	PictureTypeArtist         = 8
+	PictureTypeConductor      = 9
+	PictureTypeBand           = 10
+	PictureTypeComposer       = 11
+	PictureTypeLyricist       = 12
+	PictureTypeLocation       = 13
+	PictureTypeRecording      = 14
+	PictureTypePerformance    = 15
+	PictureTypeScreenCapture  = 16
+	PictureTypeFish           = 17
+	PictureTypeIllustration   = 18
+	PictureTypeBandLogo       = 19
+	PictureTypePublisherLogo  = 20
+	pictureTypeMaxValidNumber = PictureTypePublisherLogo
+)
+
+var PictureTypes = map[string]int{
+	"other":          PictureTypeOther,
+	"icon":           PictureTypeFileIcon,
+	"other-icon":     PictureTypeOtherFileIcon,
+	"front":          PictureTypeFront,
+	"back":           PictureTypeBack,
+	"leaflet":        PictureTypeLeaflet,
+	"media":          PictureTypeMedia,
+	"lead-artist":    PictureTypeLeadArtist,
+	"artist":         PictureTypeArtist,
+	"conductor":      PictureTypeConductor,
+	"band":           PictureTypeBand,
+	"composer":       PictureTypeComposer,
+	"lyricist":       PictureTypeLyricist,
+	"location":       PictureTypeLocation,
+	"recording":      PictureTypeRecording,
+	"performance":    PictureTypePerformance,
+	"screen-capture": PictureTypeScreenCapture,
+	"fish":           PictureTypeFish,
+	"illustration":   PictureTypeIllustration,
+	"band-logo":      PictureTypeBandLogo,
+	"publisher-logo": PictureTypePublisherLogo,
+}
+
+// PictureTypeNames returns the names of all picture types ordered by their number.
+func PictureTypeNames() []string {
+	names := make([]string, 0, len(PictureTypes))
+	for name := range PictureTypes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return PictureTypes[names[i]] < PictureTypes[names[j]]
+	})
+	return names
+}
+
+// PictureTypeName returns the name of a picture type number.
+func PictureTypeName(pictureType int) string {
+	for name, number := range PictureTypes {
+		if number == pictureType {
+			return name
+		}
+	}
+	return strconv.Itoa(pictureType)
+}
+
+// ParsePictureType parses a picture type given either by its name or its number.
+func ParsePictureType(pictureType string) (int, error) {
+	pictureType = strings.ToLower(strings.TrimSpace(pictureType))
+	if number, found := PictureTypes[pictureType]; found {
+		return number, nil
+	}
+
+	number, err := strconv.Atoi(pictureType)
+	if err != nil || number < 0 || number > pictureTypeMaxValidNumber {
+		return 0, fmt.Errorf("unknown picture type %q, valid types are %v", pictureType, PictureTypeNames())
+	}
+	return number, nil
+}
+
+// ParsePictureTypes parses a list of picture types given either by their name or their number.
+func ParsePictureTypes(pictureTypes []string) ([]int, error) {
+	var parsed []int
+	for _, pictureType := range pictureTypes {
+		number, err := ParsePictureType(pictureType)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, number)
+	}
+	return parsed, nil
+}
+
+// PictureFilter selects picture blocks of a file. An empty filter selects all pictures.
+type PictureFilter struct {
+	Types     []int
+	Indexes   []int
+	MIMETypes []string
+}
+
+func (f PictureFilter) IsEmpty() bool {
+	return len(f.Types) == 0 && len(f.Indexes) == 0 && len(f.MIMETypes) == 0
+}
+
+// Matches checks whether the picture at the given (1-based) index among a file's pictures is selected.
+func (f PictureFilter) Matches(index int, img FlacImage) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, img.TypeID()) {
+		return false
+	}
+
+	if len(f.Indexes) > 0 && !slices.Contains(f.Indexes, index) {
+		return false
+	}
+
+	if len(f.MIMETypes) > 0 && !slices.ContainsFunc(f.MIMETypes, func(mimeType string) bool {
+		return strings.EqualFold(mimeType, img.MIMEType)
+	}) {
+		return false
+	}
+
+	return true
+}
+
+// Filter returns the pictures that are selected by the filter.
+func (f PictureFilter) Filter(images []FlacImage) []FlacImage {
+	var matched []FlacImage
+	for i, img := range images {
+		if f.Matches(i+1, img) {
+			matched = append(matched, img)
+		}
+	}
+	return matched
+}
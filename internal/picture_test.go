@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePictureType(t *testing.T) {
+	tests := []struct {
+		pictureType string
+		want        int
+		wantErr     bool
+	}{
+		{pictureType: "front", want: PictureTypeFront},
+		{pictureType: "Back", want: PictureTypeBack},
+		{pictureType: "8", want: PictureTypeArtist},
+		{pictureType: "21", wantErr: true},
+		{pictureType: "poster", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pictureType, func(t *testing.T) {
+			got, err := ParsePictureType(tt.pictureType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePictureType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParsePictureType() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPictureFilter_Filter(t *testing.T) {
+	images := []FlacImage{
+		{BlockNumber: 2, Type: "3 (Cover (front))", MIMEType: "image/jpeg"},
+		{BlockNumber: 3, Type: "4 (Cover (back))", MIMEType: "image/jpeg"},
+		{BlockNumber: 4, Type: "3 (Cover (front))", MIMEType: "image/png"},
+	}
+
+	tests := []struct {
+		name   string
+		filter PictureFilter
+		want   []int
+	}{
+		{name: "empty", filter: PictureFilter{}, want: []int{2, 3, 4}},
+		{name: "type", filter: PictureFilter{Types: []int{PictureTypeFront}}, want: []int{2, 4}},
+		{name: "index", filter: PictureFilter{Indexes: []int{2}}, want: []int{3}},
+		{name: "type and mime", filter: PictureFilter{Types: []int{PictureTypeFront}, MIMETypes: []string{"IMAGE/PNG"}}, want: []int{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, img := range tt.filter.Filter(images) {
+				got = append(got, img.BlockNumber)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"errors"
+	"sort"
+)
+
+// TagValue is the value of a tag as observed in a single file.
+type TagValue struct {
+	File  string
+	Value string
+}
+
+// DistinctValues returns the distinct values ordered by the number of files they appear in.
+// Values that appear equally often keep the order of their first occurrence.
+func DistinctValues(values []TagValue) []string {
+	counts := make(map[string]int)
+	var distinct []string
+	for _, value := range values {
+		if _, found := counts[value.Value]; !found {
+			distinct = append(distinct, value.Value)
+		}
+		counts[value.Value]++
+	}
+
+	sort.SliceStable(distinct, func(i, j int) bool {
+		return counts[distinct[i]] > counts[distinct[j]]
+	})
+
+	return distinct
+}
+
+// ResolveMajority returns the value that appears in more files than any other value.
+func ResolveMajority(values []TagValue) (string, error) {
+	if len(values) == 0 {
+		return "", errors.New("no values provided")
+	}
+
+	counts := make(map[string]int)
+	for _, value := range values {
+		counts[value.Value]++
+	}
+
+	distinct := DistinctValues(values)
+	if len(distinct) > 1 && counts[distinct[0]] == counts[distinct[1]] {
+		return "", ErrNoMajority
+	}
+
+	return distinct[0], nil
+}
+
+// ResolveFirst returns the value of the first file.
+func ResolveFirst(values []TagValue) (string, error) {
+	if len(values) == 0 {
+		return "", errors.New("no values provided")
+	}
+
+	return values[0].Value, nil
+}
+
+// Outliers returns the files whose value differs from the chosen value.
+func Outliers(values []TagValue, chosen string) []string {
+	var files []string
+	for _, value := range values {
+		if value.Value != chosen {
+			files = append(files, value.File)
+		}
+	}
+	return files
+}
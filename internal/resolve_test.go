@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveMajority(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []TagValue
+		want    string
+		wantErr error
+	}{
+		{
+			name: "single outlier",
+			values: []TagValue{
+				{File: "01.flac", Value: "1999"},
+				{File: "02.flac", Value: "2000"},
+				{File: "03.flac", Value: "2000"},
+			},
+			want: "2000",
+		},
+		{
+			name: "tie",
+			values: []TagValue{
+				{File: "01.flac", Value: "1999"},
+				{File: "02.flac", Value: "2000"},
+			},
+			wantErr: ErrNoMajority,
+		},
+		{
+			name: "uniform",
+			values: []TagValue{
+				{File: "01.flac", Value: "2000"},
+				{File: "02.flac", Value: "2000"},
+			},
+			want: "2000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveMajority(tt.values)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResolveMajority() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolveMajority() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistinctValues(t *testing.T) {
+	values := []TagValue{
+		{File: "01.flac", Value: "1999"},
+		{File: "02.flac", Value: "2001"},
+		{File: "03.flac", Value: "2000"},
+		{File: "04.flac", Value: "2000"},
+	}
+
+	want := []string{"2000", "1999", "2001"}
+	if got := DistinctValues(values); !reflect.DeepEqual(got, want) {
+		t.Errorf("DistinctValues() got = %v, want %v", got, want)
+	}
+
+	wantOutliers := []string{"01.flac", "02.flac"}
+	if got := Outliers(values, "2000"); !reflect.DeepEqual(got, wantOutliers) {
+		t.Errorf("Outliers() got = %v, want %v", got, wantOutliers)
+	}
+}
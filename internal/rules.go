@@ -0,0 +1,390 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v3"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var Severities = []Severity{SeverityInfo, SeverityWarning, SeverityError}
+
+// Rank orders severities from info (0) to error (2), unknown severities rank as warning.
+func (s Severity) Rank() int {
+	if rank := slices.Index(Severities, s); rank >= 0 {
+		return rank
+	}
+	return slices.Index(Severities, SeverityWarning)
+}
+
+// ParseSeverity parses the name of a severity.
+func ParseSeverity(severity string) (Severity, error) {
+	parsed := Severity(strings.ToLower(strings.TrimSpace(severity)))
+	if !slices.Contains(Severities, parsed) {
+		return "", fmt.Errorf("unknown severity %q, valid severities are %v", severity, Severities)
+	}
+	return parsed, nil
+}
+
+const (
+	RuleScopeTrack = "track"
+	RuleScopeAlbum = "album"
+)
+
+// Rule is a check on the tags of a single track or of all tracks of an album directory. A rule only
+// applies to tracks or albums whose tags match all its When conditions.
+//
+// Example:
+//
+//	name: classical-composer
+//	severity: error
+//	when:
+//	  GENRE: Classical
+//	require: [COMPOSER]
+type Rule struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Severity    Severity `yaml:"severity,omitempty" json:"severity,omitempty"`
+	// Scope is either "track" (default) or "album"
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"`
+	// When maps tags to regular expressions their values have to match for the rule to apply
+	When map[string]string `yaml:"when,omitempty" json:"when,omitempty"`
+	// Require lists the tags that must not be missing or empty
+	Require []string `yaml:"require,omitempty" json:"require,omitempty"`
+	// Match maps tags to regular expressions their values must match
+	Match map[string]string `yaml:"match,omitempty" json:"match,omitempty"`
+	// Allowed maps tags to the list of values they may have
+	Allowed map[string][]string `yaml:"allowed,omitempty" json:"allowed,omitempty"`
+	// Forbid lists tags that must not be present
+	Forbid []string `yaml:"forbid,omitempty" json:"forbid,omitempty"`
+	// OnlyTags lists the tags that may be present, all other tags are reported. Synthetic tags are ignored.
+	OnlyTags []string `yaml:"only_tags,omitempty" json:"only_tags,omitempty"`
+	// Uniform lists the tags that must have a single value across an album, only used with album scope
+	Uniform []string `yaml:"uniform,omitempty" json:"uniform,omitempty"`
+
+	when  map[string]*regexp.Regexp
+	match map[string]*regexp.Regexp
+}
+
+// RuleSet is the set of rules evaluated by analyze.
+type RuleSet struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Finding is a violation of a rule by a file or an album directory.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Subject is the file or, for album scoped rules, the directory violating the rule
+	Subject string `json:"subject"`
+	Tag     string `json:"tag,omitempty"`
+	Message string `json:"message"`
+}
+
+// SortFindings orders findings by rule, subject, tag and message.
+func SortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		if a.Tag != b.Tag {
+			return a.Tag < b.Tag
+		}
+		return a.Message < b.Message
+	})
+}
+
+// LoadRuleSet reads and validates a rule set from a YAML file.
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ruleSet RuleSet
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("could not parse rules %q: %w", path, err)
+	}
+
+	if err := ruleSet.Compile(); err != nil {
+		return nil, fmt.Errorf("invalid rules %q: %w", path, err)
+	}
+
+	return &ruleSet, nil
+}
+
+// Compile validates the rules, fills in defaults and compiles their regular expressions.
+func (rs *RuleSet) Compile() error {
+	var errs error
+	names := make(map[string]bool)
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		if err := rule.compile(); err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		if names[rule.Name] {
+			errs = multierr.Append(errs, fmt.Errorf("duplicate rule %q", rule.Name))
+		}
+		names[rule.Name] = true
+	}
+	return errs
+}
+
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("rule without name")
+	}
+
+	if r.Severity == "" {
+		r.Severity = SeverityWarning
+	}
+	if _, err := ParseSeverity(string(r.Severity)); err != nil {
+		return fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+
+	if r.Scope == "" {
+		r.Scope = RuleScopeTrack
+	}
+	if r.Scope != RuleScopeTrack && r.Scope != RuleScopeAlbum {
+		return fmt.Errorf("rule %q: unknown scope %q, valid scopes are %q and %q", r.Name, r.Scope, RuleScopeTrack, RuleScopeAlbum)
+	}
+
+	if len(r.Uniform) > 0 && r.Scope != RuleScopeAlbum {
+		return fmt.Errorf("rule %q: uniform tags require the album scope", r.Name)
+	}
+
+	var err error
+	if r.when, err = compileTagPatterns(r.When); err != nil {
+		return fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+	if r.match, err = compileTagPatterns(r.Match); err != nil {
+		return fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+
+	return nil
+}
+
+// compileTagPatterns compiles the patterns anchored to match the whole value.
+func compileTagPatterns(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp, len(patterns))
+	for tag, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for %s: %w", tag, err)
+		}
+		compiled[strings.ToUpper(tag)] = re
+	}
+	return compiled, nil
+}
+
+// Evaluate checks the metadata of all files against the rules. The findings are ordered by rule,
+// subject and tag.
+// metadata is file - { tag: value }
+func (rs *RuleSet) Evaluate(metadata map[string]map[string]string) []Finding {
+	albums := make(map[string][]string)
+	for file := range metadata {
+		dir := filepath.Dir(file)
+		albums[dir] = append(albums[dir], file)
+	}
+
+	findings := make([]Finding, 0)
+	for _, rule := range rs.Rules {
+		var ruleFindings []Finding
+		if rule.Scope == RuleScopeAlbum {
+			for dir, files := range albums {
+				ruleFindings = append(ruleFindings, rule.evaluateAlbum(dir, files, metadata)...)
+			}
+		} else {
+			for file, tags := range metadata {
+				ruleFindings = append(ruleFindings, rule.evaluateTrack(file, tags)...)
+			}
+		}
+
+		sort.Slice(ruleFindings, func(i, j int) bool {
+			if ruleFindings[i].Subject != ruleFindings[j].Subject {
+				return ruleFindings[i].Subject < ruleFindings[j].Subject
+			}
+			if ruleFindings[i].Tag != ruleFindings[j].Tag {
+				return ruleFindings[i].Tag < ruleFindings[j].Tag
+			}
+			return ruleFindings[i].Message < ruleFindings[j].Message
+		})
+		findings = append(findings, ruleFindings...)
+	}
+
+	return findings
+}
+
+func (r Rule) finding(subject, tag, message string) Finding {
+	return Finding{
+		Rule:     r.Name,
+		Severity: r.Severity,
+		Subject:  subject,
+		Tag:      tag,
+		Message:  message,
+	}
+}
+
+func (r Rule) evaluateTrack(file string, tags map[string]string) []Finding {
+	for tag, re := range r.when {
+		if !re.MatchString(tags[tag]) {
+			return nil
+		}
+	}
+
+	var findings []Finding
+	for _, tag := range r.Require {
+		if tags[strings.ToUpper(tag)] == "" {
+			findings = append(findings, r.finding(file, strings.ToUpper(tag), "missing"))
+		}
+	}
+
+	for tag, re := range r.match {
+		if value, found := tags[tag]; found && !re.MatchString(value) {
+			findings = append(findings, r.finding(file, tag, fmt.Sprintf("%q does not match %q", value, r.patternFor(r.Match, tag))))
+		}
+	}
+
+	for tag, allowed := range r.Allowed {
+		tag = strings.ToUpper(tag)
+		if value, found := tags[tag]; found && !slices.Contains(allowed, value) {
+			findings = append(findings, r.finding(file, tag, fmt.Sprintf("%q is not an allowed value", value)))
+		}
+	}
+
+	for _, tag := range r.Forbid {
+		if value, found := tags[strings.ToUpper(tag)]; found {
+			findings = append(findings, r.finding(file, strings.ToUpper(tag), fmt.Sprintf("forbidden tag with value %q", value)))
+		}
+	}
+
+	if len(r.OnlyTags) > 0 {
+		for tag, value := range tags {
+			if !strings.HasPrefix(tag, "_") && !slices.ContainsFunc(r.OnlyTags, func(only string) bool {
+				return strings.EqualFold(only, tag)
+			}) {
+				findings = append(findings, r.finding(file, tag, fmt.Sprintf("undesired tag with value %q", value)))
+			}
+		}
+	}
+
+	return findings
+}
+
+// evaluateAlbum checks the tracks of an album directory. A condition holds if the values of all tracks
+// match. Required tags must be present in every track, all other checks are applied to the distinct
+// values of the album.
+func (r Rule) evaluateAlbum(dir string, files []string, metadata map[string]map[string]string) []Finding {
+	values := func(tag string) []string {
+		var distinct []string
+		for _, file := range files {
+			if value, found := metadata[file][tag]; found && !slices.Contains(distinct, value) {
+				distinct = append(distinct, value)
+			}
+		}
+		sort.Strings(distinct)
+		return distinct
+	}
+
+	for tag, re := range r.when {
+		for _, file := range files {
+			if !re.MatchString(metadata[file][tag]) {
+				return nil
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, tag := range r.Require {
+		tag = strings.ToUpper(tag)
+		missing := 0
+		for _, file := range files {
+			if metadata[file][tag] == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			findings = append(findings, r.finding(dir, tag, fmt.Sprintf("missing in %d of %d tracks", missing, len(files))))
+		}
+	}
+
+	for tag, re := range r.match {
+		for _, value := range values(tag) {
+			if !re.MatchString(value) {
+				findings = append(findings, r.finding(dir, tag, fmt.Sprintf("%q does not match %q", value, r.patternFor(r.Match, tag))))
+			}
+		}
+	}
+
+	for tag, allowed := range r.Allowed {
+		tag = strings.ToUpper(tag)
+		for _, value := range values(tag) {
+			if !slices.Contains(allowed, value) {
+				findings = append(findings, r.finding(dir, tag, fmt.Sprintf("%q is not an allowed value", value)))
+			}
+		}
+	}
+
+	for _, tag := range r.Forbid {
+		tag = strings.ToUpper(tag)
+		if found := values(tag); len(found) > 0 {
+			findings = append(findings, r.finding(dir, tag, fmt.Sprintf("forbidden tag with values %q", found)))
+		}
+	}
+
+	if len(r.OnlyTags) > 0 {
+		undesired := make(map[string]bool)
+		for _, file := range files {
+			for tag := range metadata[file] {
+				if !strings.HasPrefix(tag, "_") && !slices.ContainsFunc(r.OnlyTags, func(only string) bool {
+					return strings.EqualFold(only, tag)
+				}) {
+					undesired[tag] = true
+				}
+			}
+		}
+		for tag := range undesired {
+			findings = append(findings, r.finding(dir, tag, "undesired tag"))
+		}
+	}
+
+	for _, tag := range r.Uniform {
+		tag = strings.ToUpper(tag)
+		if found := values(tag); len(found) > 1 {
+			findings = append(findings, r.finding(dir, tag, fmt.Sprintf("multiple values %q", found)))
+		}
+	}
+
+	return findings
+}
+
+// patternFor returns the pattern of a tag as configured, regardless of the tag's case
+func (r Rule) patternFor(patterns map[string]string, tag string) string {
+	for configured, pattern := range patterns {
+		if strings.EqualFold(configured, tag) {
+			return pattern
+		}
+	}
+	return ""
+}
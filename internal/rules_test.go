@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRuleSetEvaluate(t *testing.T) {
+	metadata := map[string]map[string]string{
+		"a/01.flac": {TagArtist: "Bach", TagGenre: "Classical", TagDate: "1999", TagTitle: "Prelude"},
+		"a/02.flac": {TagArtist: "Bach", TagGenre: "Classical", TagDate: "99", TagComposer: "J.S. Bach", TagTitle: "Fugue"},
+		"b/01.flac": {TagArtist: "Björk", TagGenre: "Pop", TagDate: "1997", "REPLAYGAIN": "x"},
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want []Finding
+	}{
+		{
+			name: "conditional requirement",
+			rule: Rule{Name: "composer", Severity: SeverityError, When: map[string]string{"genre": "Classical"}, Require: []string{"composer"}},
+			want: []Finding{
+				{Rule: "composer", Severity: SeverityError, Subject: "a/01.flac", Tag: TagComposer, Message: "missing"},
+			},
+		},
+		{
+			name: "regex constraint",
+			rule: Rule{Name: "date", Match: map[string]string{TagDate: `\d{4}`}},
+			want: []Finding{
+				{Rule: "date", Severity: SeverityWarning, Subject: "a/02.flac", Tag: TagDate, Message: `"99" does not match "\\d{4}"`},
+			},
+		},
+		{
+			name: "allowed values",
+			rule: Rule{Name: "genres", Severity: SeverityInfo, Allowed: map[string][]string{TagGenre: {"Classical", "Rock"}}},
+			want: []Finding{
+				{Rule: "genres", Severity: SeverityInfo, Subject: "b/01.flac", Tag: TagGenre, Message: `"Pop" is not an allowed value`},
+			},
+		},
+		{
+			name: "only tags",
+			rule: Rule{Name: "tags", OnlyTags: []string{TagArtist, TagGenre, TagDate, TagTitle, TagComposer}},
+			want: []Finding{
+				{Rule: "tags", Severity: SeverityWarning, Subject: "b/01.flac", Tag: "REPLAYGAIN", Message: `undesired tag with value "x"`},
+			},
+		},
+		{
+			name: "album scope",
+			rule: Rule{Name: "album", Scope: RuleScopeAlbum, Require: []string{TagComposer}, Uniform: []string{TagDate}, When: map[string]string{TagGenre: "Classical"}},
+			want: []Finding{
+				{Rule: "album", Severity: SeverityWarning, Subject: "a", Tag: TagComposer, Message: "missing in 1 of 2 tracks"},
+				{Rule: "album", Severity: SeverityWarning, Subject: "a", Tag: TagDate, Message: `multiple values ["1999" "99"]`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleSet := RuleSet{Rules: []Rule{tt.rule}}
+			if err := ruleSet.Compile(); err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			if got := ruleSet.Evaluate(metadata); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSetCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr bool
+	}{
+		{name: "valid", rules: []Rule{{Name: "a"}, {Name: "b", Scope: RuleScopeAlbum, Uniform: []string{TagAlbum}}}},
+		{name: "missing name", rules: []Rule{{}}, wantErr: true},
+		{name: "duplicate name", rules: []Rule{{Name: "a"}, {Name: "a"}}, wantErr: true},
+		{name: "unknown severity", rules: []Rule{{Name: "a", Severity: "fatal"}}, wantErr: true},
+		{name: "uniform on track", rules: []Rule{{Name: "a", Uniform: []string{TagAlbum}}}, wantErr: true},
+		{name: "invalid pattern", rules: []Rule{{Name: "a", Match: map[string]string{TagDate: "("}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleSet := RuleSet{Rules: tt.rules}
+			if err := ruleSet.Compile(); (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	config := `rules:
+  - name: classical-composer
+    severity: error
+    when:
+      GENRE: Classical
+    require: [COMPOSER]
+  - name: album-tags
+    scope: album
+    uniform: [ALBUM, DATE]
+`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ruleSet, err := LoadRuleSet(path)
+	if err != nil {
+		t.Fatalf("LoadRuleSet() error = %v", err)
+	}
+
+	if len(ruleSet.Rules) != 2 || ruleSet.Rules[0].Severity != SeverityError || ruleSet.Rules[1].Severity != SeverityWarning || ruleSet.Rules[1].Scope != RuleScopeAlbum {
+		t.Errorf("LoadRuleSet() got = %+v", ruleSet.Rules)
+	}
+}
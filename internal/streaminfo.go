@@ -0,0 +1,320 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soerenschneider/flac-mate/pkg"
+)
+
+// Synthetic tags describing the technical properties of the audio stream
+const (
+	SyntheticBitDepthTag   = "_bitdepth"
+	SyntheticSampleRateTag = "_samplerate"
+	SyntheticChannelsTag   = "_channels"
+	SyntheticCodecTag      = "_codec"
+	SyntheticDurationTag   = "_duration"
+	SyntheticAlbumSizeTag  = "_albumsize"
+)
+
+// SyntheticMappings maps the short notation of the synthetic tags that can be used in naming schemes
+var SyntheticMappings = map[string]string{
+	"bd": SyntheticBitDepthTag,
+	"sr": SyntheticSampleRateTag,
+	"ch": SyntheticChannelsTag,
+	"co": SyntheticCodecTag,
+	"du": SyntheticDurationTag,
+	"as": SyntheticAlbumSizeTag,
+}
+
+const codecFlac = "FLAC"
+
+var ErrInconsistentStreamInfo = errors.New("tracks disagree on stream properties")
+
+// noAudioMD5 is the MD5 signature of STREAMINFO blocks written by encoders that did not compute it
+const noAudioMD5 = "00000000000000000000000000000000"
+
+// StreamInfo holds the technical properties of a FLAC file as stored in its STREAMINFO block, and the
+// vendor string of its VORBIS_COMMENT block.
+type StreamInfo struct {
+	SampleRate    int
+	BitsPerSample int
+	Channels      int
+	TotalSamples  int64
+	FileSize      int64
+	MD5           string
+	Vendor        string
+}
+
+// HasAudioMD5 returns whether the encoder stored the MD5 signature of the unencoded audio.
+func (s StreamInfo) HasAudioMD5() bool {
+	return s.MD5 != "" && s.MD5 != noAudioMD5
+}
+
+// Duration returns the playing time of the stream.
+func (s StreamInfo) Duration() time.Duration {
+	if s.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(s.TotalSamples) * time.Second / time.Duration(s.SampleRate)
+}
+
+// FetchStreamInfo reads the STREAMINFO block of a given file.
+func FetchStreamInfo(filepath string) (StreamInfo, error) {
+	info, err := os.Stat(filepath)
+	if err != nil {
+		return StreamInfo{}, err
+	}
+
+	args := []string{
+		"--show-sample-rate",
+		"--show-bps",
+		"--show-channels",
+		"--show-total-samples",
+		"--show-md5sum",
+		"--show-vendor-tag",
+		filepath,
+	}
+
+	cmd := exec.Command("metaflac", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			stderrOutput := strings.TrimSpace(stderr.String())
+			if stderrOutput != "" {
+				return StreamInfo{}, fmt.Errorf("metaflac show failed (exit code %d): %s", exitError.ExitCode(), stderrOutput)
+			}
+			return StreamInfo{}, fmt.Errorf("metaflac show failed with exit code %d", exitError.ExitCode())
+		}
+		return StreamInfo{}, fmt.Errorf("metaflac show failed to execute: %v", err)
+	}
+
+	streamInfo, err := parseStreamInfo(string(output))
+	if err != nil {
+		return StreamInfo{}, fmt.Errorf("unexpected metaflac output for %s: %w", filepath, err)
+	}
+	streamInfo.FileSize = info.Size()
+
+	return streamInfo, nil
+}
+
+// parseStreamInfo parses the output of metaflac for the options used by FetchStreamInfo. The values
+// are printed in the order the options were given, a file without VORBIS_COMMENT block has no vendor.
+func parseStreamInfo(output string) (StreamInfo, error) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) != 5 && len(lines) != 6 {
+		return StreamInfo{}, fmt.Errorf("%q", output)
+	}
+
+	var values [4]int64
+	for i := range values {
+		var err error
+		values[i], err = strconv.ParseInt(strings.TrimSpace(lines[i]), 10, 64)
+		if err != nil {
+			return StreamInfo{}, fmt.Errorf("could not parse %q: %w", lines[i], err)
+		}
+	}
+
+	streamInfo := StreamInfo{
+		SampleRate:    int(values[0]),
+		BitsPerSample: int(values[1]),
+		Channels:      int(values[2]),
+		TotalSamples:  values[3],
+		MD5:           strings.TrimSpace(lines[4]),
+	}
+	if len(lines) == 6 {
+		streamInfo.Vendor = strings.TrimSpace(lines[5])
+	}
+
+	return streamInfo, nil
+}
+
+// TrackTechnicalMetadata returns the synthetic technical tags of a single track.
+func TrackTechnicalMetadata(info StreamInfo) map[string]string {
+	return map[string]string{
+		SyntheticBitDepthTag:   strconv.Itoa(info.BitsPerSample),
+		SyntheticSampleRateTag: formatSampleRate(info.SampleRate),
+		SyntheticChannelsTag:   strconv.Itoa(info.Channels),
+		SyntheticCodecTag:      codecFlac,
+		SyntheticDurationTag:   formatDuration(info.Duration()),
+	}
+}
+
+// AlbumTechnicalMetadata returns the synthetic technical tags of an album. The duration is the playing
+// time of all tracks. If the tracks disagree on bit depth or sample rate, those tags are omitted and
+// ErrInconsistentStreamInfo is returned alongside the remaining tags.
+func AlbumTechnicalMetadata(infos []StreamInfo) (map[string]string, error) {
+	if len(infos) == 0 {
+		return nil, errors.New("no stream info provided")
+	}
+
+	var duration time.Duration
+	var size int64
+	bitDepths := make(map[int]bool)
+	sampleRates := make(map[int]bool)
+	channels := make(map[int]bool)
+	for _, info := range infos {
+		duration += info.Duration()
+		size += info.FileSize
+		bitDepths[info.BitsPerSample] = true
+		sampleRates[info.SampleRate] = true
+		channels[info.Channels] = true
+	}
+
+	metadata := map[string]string{
+		SyntheticCodecTag:     codecFlac,
+		SyntheticDurationTag:  formatDuration(duration),
+		SyntheticAlbumSizeTag: pkg.HumanSize(size),
+	}
+
+	if len(channels) == 1 {
+		metadata[SyntheticChannelsTag] = strconv.Itoa(infos[0].Channels)
+	}
+
+	var inconsistent []string
+	if len(bitDepths) == 1 {
+		metadata[SyntheticBitDepthTag] = strconv.Itoa(infos[0].BitsPerSample)
+	} else {
+		inconsistent = append(inconsistent, "bit depth")
+	}
+
+	if len(sampleRates) == 1 {
+		metadata[SyntheticSampleRateTag] = formatSampleRate(infos[0].SampleRate)
+	} else {
+		inconsistent = append(inconsistent, "sample rate")
+	}
+
+	if len(inconsistent) > 0 {
+		return metadata, fmt.Errorf("%w: %s", ErrInconsistentStreamInfo, strings.Join(inconsistent, ", "))
+	}
+
+	return metadata, nil
+}
+
+// formatSampleRate formats the sample rate in kHz, e.g. 44100 becomes "44.1" and 96000 becomes "96".
+func formatSampleRate(sampleRate int) string {
+	return strconv.FormatFloat(float64(sampleRate)/1000, 'f', -1, 64)
+}
+
+// formatDuration formats a duration as minutes and seconds, e.g. "3m25s".
+func formatDuration(duration time.Duration) string {
+	seconds := int(duration.Round(time.Second).Seconds())
+	return fmt.Sprintf("%dm%02ds", seconds/60, seconds%60)
+}
+
+// Names of the technical consistency checks
+const (
+	CheckMixedResolution = "mixed-resolution"
+	CheckMixedChannels   = "mixed-channels"
+	CheckMixedEncoders   = "mixed-encoders"
+	CheckMissingAudioMD5 = "missing-audio-md5"
+)
+
+// CheckTechnicalConsistency reports album directories whose tracks mix bit depths and sample rates,
+// channel counts or encoder vendors, and tracks without an audio MD5 signature. The findings are
+// ordered by check and subject.
+// infos is file - stream info
+func CheckTechnicalConsistency(infos map[string]StreamInfo) []Finding {
+	albums := make(map[string][]string)
+	for file := range infos {
+		dir := filepath.Dir(file)
+		albums[dir] = append(albums[dir], file)
+	}
+
+	var findings []Finding
+	for dir, files := range albums {
+		sort.Strings(files)
+
+		resolutions := newValueCounter()
+		channels := newValueCounter()
+		vendors := newValueCounter()
+		for _, file := range files {
+			info := infos[file]
+			resolutions.add(fmt.Sprintf("%d/%s", info.BitsPerSample, formatSampleRate(info.SampleRate)))
+			channels.add(formatChannels(info.Channels))
+			vendor := info.Vendor
+			if vendor == "" {
+				vendor = "unknown"
+			}
+			vendors.add(vendor)
+
+			if !info.HasAudioMD5() {
+				findings = append(findings, Finding{
+					Rule:     CheckMissingAudioMD5,
+					Severity: SeverityWarning,
+					Subject:  file,
+					Message:  "no MD5 signature of the audio data, integrity cannot be verified",
+				})
+			}
+		}
+
+		if resolutions.mixed() {
+			findings = append(findings, Finding{Rule: CheckMixedResolution, Severity: SeverityWarning, Subject: dir, Message: resolutions.String()})
+		}
+		if channels.mixed() {
+			findings = append(findings, Finding{Rule: CheckMixedChannels, Severity: SeverityWarning, Subject: dir, Message: channels.String()})
+		}
+		if vendors.mixed() {
+			findings = append(findings, Finding{Rule: CheckMixedEncoders, Severity: SeverityInfo, Subject: dir, Message: vendors.String()})
+		}
+	}
+
+	SortFindings(findings)
+	return findings
+}
+
+// formatChannels names the common channel counts, e.g. 2 becomes "stereo".
+func formatChannels(channels int) string {
+	switch channels {
+	case 1:
+		return "mono"
+	case 2:
+		return "stereo"
+	}
+	return fmt.Sprintf("%d channels", channels)
+}
+
+// valueCounter counts the tracks per distinct value in order of appearance
+type valueCounter struct {
+	values []string
+	counts map[string]int
+}
+
+func newValueCounter() *valueCounter {
+	return &valueCounter{counts: make(map[string]int)}
+}
+
+func (c *valueCounter) add(value string) {
+	if _, found := c.counts[value]; !found {
+		c.values = append(c.values, value)
+	}
+	c.counts[value]++
+}
+
+func (c *valueCounter) mixed() bool {
+	return len(c.values) > 1
+}
+
+// String lists the values and their number of tracks, e.g. "16/44.1 (10 tracks), 24/96 (2 tracks)".
+func (c *valueCounter) String() string {
+	parts := make([]string, 0, len(c.values))
+	for _, value := range c.values {
+		unit := "tracks"
+		if c.counts[value] == 1 {
+			unit = "track"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d %s)", value, c.counts[value], unit))
+	}
+	return strings.Join(parts, ", ")
+}
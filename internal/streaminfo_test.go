@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAlbumTechnicalMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		infos   []StreamInfo
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "consistent album",
+			infos: []StreamInfo{
+				{SampleRate: 96000, BitsPerSample: 24, Channels: 2, TotalSamples: 96000 * 200, FileSize: 200_000_000},
+				{SampleRate: 96000, BitsPerSample: 24, Channels: 2, TotalSamples: 96000 * 100, FileSize: 100_000_000},
+			},
+			want: map[string]string{
+				SyntheticBitDepthTag:   "24",
+				SyntheticSampleRateTag: "96",
+				SyntheticChannelsTag:   "2",
+				SyntheticCodecTag:      "FLAC",
+				SyntheticDurationTag:   "5m00s",
+				SyntheticAlbumSizeTag:  "300MB",
+			},
+		},
+		{
+			name: "mixed sample rates",
+			infos: []StreamInfo{
+				{SampleRate: 44100, BitsPerSample: 16, Channels: 2, TotalSamples: 44100 * 61, FileSize: 1_500_000},
+				{SampleRate: 96000, BitsPerSample: 16, Channels: 2, TotalSamples: 96000 * 60, FileSize: 1_000_000},
+			},
+			want: map[string]string{
+				SyntheticBitDepthTag:  "16",
+				SyntheticChannelsTag:  "2",
+				SyntheticCodecTag:     "FLAC",
+				SyntheticDurationTag:  "2m01s",
+				SyntheticAlbumSizeTag: "2.5MB",
+			},
+			wantErr: ErrInconsistentStreamInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AlbumTechnicalMetadata(tt.infos)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AlbumTechnicalMetadata() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AlbumTechnicalMetadata() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSampleRate(t *testing.T) {
+	tests := map[int]string{
+		44100:  "44.1",
+		48000:  "48",
+		96000:  "96",
+		176400: "176.4",
+	}
+	for sampleRate, want := range tests {
+		if got := formatSampleRate(sampleRate); got != want {
+			t.Errorf("formatSampleRate(%d) got = %v, want %v", sampleRate, got, want)
+		}
+	}
+}
+
+func TestParseStreamInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    StreamInfo
+		wantErr bool
+	}{
+		{
+			name:   "with vendor",
+			output: "44100\n16\n2\n441000\nd41d8cd98f00b204e9800998ecf8427e\nreference libFLAC 1.4.3 20230623\n",
+			want:   StreamInfo{SampleRate: 44100, BitsPerSample: 16, Channels: 2, TotalSamples: 441000, MD5: "d41d8cd98f00b204e9800998ecf8427e", Vendor: "reference libFLAC 1.4.3 20230623"},
+		},
+		{
+			name:   "without vendor",
+			output: "96000\n24\n1\n960000\n00000000000000000000000000000000\n",
+			want:   StreamInfo{SampleRate: 96000, BitsPerSample: 24, Channels: 1, TotalSamples: 960000, MD5: noAudioMD5},
+		},
+		{
+			name:    "garbage",
+			output:  "44100 16 2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStreamInfo(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreamInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseStreamInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTechnicalConsistency(t *testing.T) {
+	const md5 = "d41d8cd98f00b204e9800998ecf8427e"
+	cd := StreamInfo{SampleRate: 44100, BitsPerSample: 16, Channels: 2, MD5: md5, Vendor: "reference libFLAC 1.4.3 20230623"}
+
+	hiRes := cd
+	hiRes.SampleRate, hiRes.BitsPerSample = 96000, 24
+
+	mono := cd
+	mono.Channels = 1
+
+	otherEncoder := cd
+	otherEncoder.Vendor = "reference libFLAC 1.2.1 20070917"
+
+	noMD5 := cd
+	noMD5.MD5 = noAudioMD5
+
+	tests := []struct {
+		name  string
+		infos map[string]StreamInfo
+		want  []Finding
+	}{
+		{
+			name:  "consistent",
+			infos: map[string]StreamInfo{"/a/1.flac": cd, "/a/2.flac": cd, "/b/1.flac": hiRes},
+		},
+		{
+			name:  "mixed",
+			infos: map[string]StreamInfo{"/a/1.flac": cd, "/a/2.flac": cd, "/a/3.flac": hiRes, "/a/4.flac": mono, "/a/5.flac": otherEncoder, "/a/6.flac": noMD5},
+			want: []Finding{
+				{Rule: CheckMissingAudioMD5, Severity: SeverityWarning, Subject: "/a/6.flac", Message: "no MD5 signature of the audio data, integrity cannot be verified"},
+				{Rule: CheckMixedChannels, Severity: SeverityWarning, Subject: "/a", Message: "stereo (5 tracks), mono (1 track)"},
+				{Rule: CheckMixedEncoders, Severity: SeverityInfo, Subject: "/a", Message: "reference libFLAC 1.4.3 20230623 (5 tracks), reference libFLAC 1.2.1 20070917 (1 track)"},
+				{Rule: CheckMixedResolution, Severity: SeverityWarning, Subject: "/a", Message: "16/44.1 (5 tracks), 24/96 (1 track)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckTechnicalConsistency(tt.infos)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckTechnicalConsistency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
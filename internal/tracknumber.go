@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NormalizeTrackNumber normalizes a TRACKNUMBER value such as "3", " 3 " or "3/12" to a zero-padded
+// number and returns the total of tracks if the value included one. Numbers are padded to two digits,
+// or three digits for albums with at least 100 tracks. The bool is false if the value is not a number.
+func NormalizeTrackNumber(value string) (string, string, bool) {
+	number, total, _ := strings.Cut(strings.TrimSpace(value), "/")
+
+	parsed, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || parsed < 0 {
+		return "", "", false
+	}
+
+	width := 2
+	var normalizedTotal string
+	if total = strings.TrimSpace(total); total != "" {
+		parsedTotal, err := strconv.Atoi(total)
+		if err != nil || parsedTotal < 0 {
+			return "", "", false
+		}
+		normalizedTotal = strconv.Itoa(parsedTotal)
+		if parsedTotal >= 100 {
+			width = 3
+		}
+	}
+
+	return fmt.Sprintf("%0*d", width, parsed), normalizedTotal, true
+}
@@ -0,0 +1,28 @@
+package internal
+
+import "testing"
+
+func TestNormalizeTrackNumber(t *testing.T) {
+	tests := []struct {
+		value     string
+		want      string
+		wantTotal string
+		wantOk    bool
+	}{
+		{value: "3", want: "03", wantOk: true},
+		{value: " 07 ", want: "07", wantOk: true},
+		{value: "3/12", want: "03", wantTotal: "12", wantOk: true},
+		{value: "4 / 012", want: "04", wantTotal: "12", wantOk: true},
+		{value: "5/120", want: "005", wantTotal: "120", wantOk: true},
+		{value: "A1", wantOk: false},
+		{value: "1/x", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, gotTotal, gotOk := NormalizeTrackNumber(tt.value)
+			if got != tt.want || gotTotal != tt.wantTotal || gotOk != tt.wantOk {
+				t.Errorf("NormalizeTrackNumber() = %q, %q, %v, want %q, %q, %v", got, gotTotal, gotOk, tt.want, tt.wantTotal, tt.wantOk)
+			}
+		})
+	}
+}
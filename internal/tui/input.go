@@ -88,3 +88,23 @@ func ReadSensitiveInput(title string) string {
 
 	return input
 }
+
+// SelectOption asks to choose one of the labels and returns the index of the chosen label.
+func SelectOption(title string, labels []string) (int, error) {
+	options := make([]huh.Option[int], 0, len(labels))
+	for i, label := range labels {
+		options = append(options, huh.NewOption(label, i))
+	}
+
+	var choice int
+	err := huh.NewSelect[int]().
+		Title(title).
+		Options(options...).
+		Value(&choice).
+		Run()
+	if err != nil {
+		return 0, err
+	}
+
+	return choice, nil
+}
@@ -0,0 +1,249 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+type PreviewProtocol string
+
+const (
+	PreviewAuto   PreviewProtocol = "auto"
+	PreviewKitty  PreviewProtocol = "kitty"
+	PreviewSixel  PreviewProtocol = "sixel"
+	PreviewBlocks PreviewProtocol = "blocks"
+
+	DefaultPreviewColumns = 40
+
+	// approximate width of a terminal cell in pixels, used to size kitty and sixel previews
+	cellWidth = 10
+
+	kittyChunkSize = 4096
+)
+
+var PreviewProtocols = []PreviewProtocol{PreviewAuto, PreviewKitty, PreviewSixel, PreviewBlocks}
+
+// ParsePreviewProtocol parses the name of a preview protocol, resolving "auto" to the protocol detected
+// for the current terminal.
+func ParsePreviewProtocol(name string) (PreviewProtocol, error) {
+	protocol := PreviewProtocol(strings.ToLower(strings.TrimSpace(name)))
+	if !slices.Contains(PreviewProtocols, protocol) {
+		return "", fmt.Errorf("unknown preview protocol %q, valid protocols are %v", name, PreviewProtocols)
+	}
+
+	if protocol == PreviewAuto {
+		return DetectPreviewProtocol(), nil
+	}
+	return protocol, nil
+}
+
+// DetectPreviewProtocol guesses the best protocol supported by the current terminal by its environment,
+// falling back to Unicode half blocks.
+func DetectPreviewProtocol() PreviewProtocol {
+	term := os.Getenv("TERM")
+	termProgram := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty":
+		return PreviewKitty
+	case termProgram == "WezTerm" || termProgram == "ghostty":
+		return PreviewKitty
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") || term == "mlterm" || strings.HasPrefix(term, "yaft"):
+		return PreviewSixel
+	}
+
+	return PreviewBlocks
+}
+
+// PrintPreview decodes the image data and renders it to stdout, columns terminal cells wide.
+func PrintPreview(data []byte, protocol PreviewProtocol, columns int) error {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("could not decode picture: %w", err)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := RenderPreview(w, img, protocol, columns); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// RenderPreview renders the image inline using the given protocol, columns terminal cells wide.
+func RenderPreview(w io.Writer, img image.Image, protocol PreviewProtocol, columns int) error {
+	if columns <= 0 {
+		columns = DefaultPreviewColumns
+	}
+
+	switch protocol {
+	case PreviewKitty:
+		return renderKitty(w, img, columns)
+	case PreviewSixel:
+		return renderSixel(w, img, columns)
+	case PreviewBlocks:
+		return renderBlocks(w, img, columns)
+	}
+
+	return fmt.Errorf("unknown preview protocol %q", protocol)
+}
+
+// scaleToWidth scales the image to the given width in pixels, keeping its aspect ratio.
+func scaleToWidth(img image.Image, width int) *image.RGBA {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 1, 1))
+	}
+
+	height := max(1, width*bounds.Dy()/bounds.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.Black, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// renderKitty transmits the image as PNG using the kitty graphics protocol
+func renderKitty(w io.Writer, img image.Image, columns int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleToWidth(img, columns*cellWidth)); err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	for offset := 0; offset < len(encoded); offset += kittyChunkSize {
+		end := min(offset+kittyChunkSize, len(encoded))
+		more := 0
+		if end < len(encoded) {
+			more = 1
+		}
+
+		// only the first chunk carries the control data
+		control := fmt.Sprintf("m=%d", more)
+		if offset == 0 {
+			control = fmt.Sprintf("a=T,f=100,c=%d,m=%d", columns, more)
+		}
+
+		if _, err := fmt.Fprintf(w, "\x1b_G%s;%s\x1b\\", control, encoded[offset:end]); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// renderSixel dithers the image to the web safe palette and encodes it as sixel graphics
+func renderSixel(w io.Writer, img image.Image, columns int) error {
+	scaled := scaleToWidth(img, columns*cellWidth)
+	bounds := scaled.Bounds()
+
+	paletted := image.NewPaletted(bounds, palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, bounds, scaled, image.Point{})
+
+	if _, err := fmt.Fprintf(w, "\x1bPq\"1;1;%d;%d", bounds.Dx(), bounds.Dy()); err != nil {
+		return err
+	}
+
+	for i, c := range paletted.Palette {
+		r, g, b, _ := c.RGBA()
+		if _, err := fmt.Fprintf(w, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff); err != nil {
+			return err
+		}
+	}
+
+	// every sixel encodes a column of six pixels, one band is written per color that occurs in it
+	for top := 0; top < bounds.Dy(); top += 6 {
+		var band bytes.Buffer
+		for colorIndex := range paletted.Palette {
+			sixels := make([]byte, bounds.Dx())
+			used := false
+			for x := 0; x < bounds.Dx(); x++ {
+				for bit := 0; bit < 6 && top+bit < bounds.Dy(); bit++ {
+					if int(paletted.ColorIndexAt(x, top+bit)) == colorIndex {
+						sixels[x] |= 1 << bit
+						used = true
+					}
+				}
+			}
+
+			if !used {
+				continue
+			}
+
+			if band.Len() > 0 {
+				band.WriteByte('$')
+			}
+			fmt.Fprintf(&band, "#%d", colorIndex)
+			writeSixelRuns(&band, sixels)
+		}
+		band.WriteByte('-')
+
+		if _, err := w.Write(band.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, "\x1b\\\n")
+	return err
+}
+
+// writeSixelRuns writes the sixels using run-length encoding for repeated characters
+func writeSixelRuns(buf *bytes.Buffer, sixels []byte) {
+	for i := 0; i < len(sixels); {
+		run := 1
+		for i+run < len(sixels) && sixels[i+run] == sixels[i] {
+			run++
+		}
+
+		char := byte(63 + sixels[i])
+		if run > 3 {
+			fmt.Fprintf(buf, "!%d%c", run, char)
+		} else {
+			for j := 0; j < run; j++ {
+				buf.WriteByte(char)
+			}
+		}
+		i += run
+	}
+}
+
+// renderBlocks draws the image with upper half blocks, every terminal cell shows two pixels using
+// true color foreground and background colors
+func renderBlocks(w io.Writer, img image.Image, columns int) error {
+	// terminal cells are about twice as high as wide, so every cell holds two square pixels
+	scaled := scaleToWidth(img, columns)
+	bounds := scaled.Bounds()
+
+	var buf bytes.Buffer
+	for y := 0; y < bounds.Dy(); y += 2 {
+		for x := 0; x < bounds.Dx(); x++ {
+			top := rgb(scaled.At(x, y))
+			bottom := top
+			if y+1 < bounds.Dy() {
+				bottom = rgb(scaled.At(x, y+1))
+			}
+			fmt.Fprintf(&buf, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		buf.WriteString("\x1b[0m\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func rgb(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
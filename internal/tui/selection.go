@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var ErrSelectionAborted = errors.New("selection aborted")
+
+var (
+	toggleKey = key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	)
+	toggleAllKey = key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle visible"),
+	)
+	acceptKey = key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "accept"),
+	)
+)
+
+type selectionItem struct {
+	index int
+	label string
+}
+
+func (i selectionItem) Title() string       { return i.label }
+func (i selectionItem) Description() string { return "" }
+func (i selectionItem) FilterValue() string { return i.label }
+
+type selectionDelegate struct {
+	selected []bool
+}
+
+func (d selectionDelegate) Height() int                               { return 1 }
+func (d selectionDelegate) Spacing() int                              { return 0 }
+func (d selectionDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+func (d selectionDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(selectionItem)
+	if !ok {
+		return
+	}
+	cursor := "  "
+	if index == m.Index() {
+		cursor = "➜ "
+	}
+	check := "[ ]"
+	if d.selected[i.index] {
+		check = "[x]"
+	}
+	_, _ = fmt.Fprintf(w, "%s%s %s", cursor, check, i.Title())
+}
+
+type selectionModel struct {
+	list     list.Model
+	selected []bool
+	accepted bool
+}
+
+func newSelectionModel(title string, labels []string, preselected bool) selectionModel {
+	selected := make([]bool, len(labels))
+	items := make([]list.Item, len(labels))
+	for i, label := range labels {
+		items[i] = selectionItem{index: i, label: label}
+		selected[i] = preselected
+	}
+
+	l := list.New(items, selectionDelegate{selected: selected}, 80, 20)
+	l.Title = title
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{toggleKey, toggleAllKey, acceptKey}
+	}
+	l.AdditionalFullHelpKeys = l.AdditionalShortHelpKeys
+
+	return selectionModel{list: l, selected: selected}
+}
+
+func (m selectionModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m selectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+		return m, nil
+	case tea.KeyMsg:
+		// while typing a filter, all keys belong to the filter input
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, toggleKey):
+			if i, ok := m.list.SelectedItem().(selectionItem); ok {
+				m.selected[i.index] = !m.selected[i.index]
+			}
+			return m, nil
+		case key.Matches(msg, toggleAllKey):
+			visible := m.list.VisibleItems()
+			allSelected := true
+			for _, listItem := range visible {
+				if i, ok := listItem.(selectionItem); ok && !m.selected[i.index] {
+					allSelected = false
+					break
+				}
+			}
+			for _, listItem := range visible {
+				if i, ok := listItem.(selectionItem); ok {
+					m.selected[i.index] = !allSelected
+				}
+			}
+			return m, nil
+		case key.Matches(msg, acceptKey):
+			m.accepted = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m selectionModel) View() string {
+	return m.list.View()
+}
+
+// SelectItems displays an interactive, filterable list of the given labels in which each entry can be
+// toggled on or off. It returns the selection state for each label in the order they were supplied.
+func SelectItems(title string, labels []string, preselected bool) ([]bool, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	p := tea.NewProgram(newSelectionModel(title, labels, preselected), tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := finalModel.(selectionModel)
+	if !ok || !m.accepted {
+		return nil, ErrSelectionAborted
+	}
+
+	return m.selected, nil
+}
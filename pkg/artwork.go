@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ArtworkKind string
+
+const (
+	ArtworkBack    ArtworkKind = "back"
+	ArtworkDisc    ArtworkKind = "disc"
+	ArtworkBooklet ArtworkKind = "booklet"
+	ArtworkOther   ArtworkKind = "other"
+)
+
+var (
+	artworkTokenRegex = regexp.MustCompile(`[a-z]+|\d+`)
+
+	backNames    = []string{"back", "rear", "inlay", "tray", "traycard"}
+	bookletNames = []string{"booklet", "book", "scan", "scans", "page", "insert", "leaflet"}
+	discNames    = []string{"cd", "disc", "disk", "matrix", "media"}
+)
+
+// ClassifyArtwork guesses the kind of an artwork file that is not the main cover, first by its name and
+// then by its aspect ratio. The returned number is the disc or page number found in the name, or 0.
+func ClassifyArtwork(dirname, filename string) (ArtworkKind, int) {
+	base := strings.ToLower(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	tokens := artworkTokenRegex.FindAllString(base, -1)
+
+	number := 0
+	for _, token := range tokens {
+		if n, err := strconv.Atoi(token); err == nil {
+			number = n
+			break
+		}
+	}
+
+	if strings.ToLower(filepath.Ext(filename)) == ".pdf" {
+		return ArtworkBooklet, number
+	}
+
+	hasToken := func(names []string) bool {
+		return slices.ContainsFunc(tokens, func(token string) bool {
+			return slices.Contains(names, token)
+		})
+	}
+
+	switch {
+	case hasToken(backNames):
+		return ArtworkBack, number
+	case hasToken(bookletNames):
+		return ArtworkBooklet, number
+	case hasToken(discNames):
+		return ArtworkDisc, number
+	}
+
+	width, height, err := ImageDimensions(filepath.Join(dirname, filename))
+	if err != nil || height == 0 {
+		return ArtworkOther, number
+	}
+
+	ratio := float64(width) / float64(height)
+	switch {
+	// jewel case inlays are about 151x118mm
+	case ratio >= 1.15 && ratio <= 1.45:
+		return ArtworkBack, number
+	// single portrait pages or landscape spreads of a booklet
+	case ratio < 0.9 || ratio >= 1.8:
+		return ArtworkBooklet, number
+	}
+
+	return ArtworkOther, number
+}
+
+// ArtworkNames returns consistent names for the given artwork files that are not the main cover,
+// e.g. back.jpg, disc1.jpg or booklet01.jpg. Files that can not be classified keep their name.
+// returns filename - new filename
+func ArtworkNames(dirname string, images []string) map[string]string {
+	type artwork struct {
+		name   string
+		number int
+	}
+
+	grouped := make(map[ArtworkKind][]artwork)
+	for _, image := range images {
+		kind, number := ClassifyArtwork(dirname, image)
+		grouped[kind] = append(grouped[kind], artwork{name: image, number: number})
+	}
+
+	for _, group := range grouped {
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].number != group[j].number {
+				return group[i].number < group[j].number
+			}
+			return strings.ToLower(group[i].name) < strings.ToLower(group[j].name)
+		})
+	}
+
+	names := make(map[string]string)
+	used := make(map[string]bool)
+	claim := func(image, base string) {
+		ext := strings.ToLower(filepath.Ext(image))
+		name := base + ext
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		used[name] = true
+		names[image] = name
+	}
+
+	// files that are not renamed keep their names reserved
+	for _, image := range grouped[ArtworkOther] {
+		used[image.name] = true
+		names[image.name] = image.name
+	}
+
+	for i, image := range grouped[ArtworkBack] {
+		if i == 0 {
+			claim(image.name, "back")
+		} else {
+			claim(image.name, fmt.Sprintf("back%d", i+1))
+		}
+	}
+
+	usedDiscs := make(map[int]bool)
+	for _, image := range grouped[ArtworkDisc] {
+		usedDiscs[image.number] = true
+	}
+	nextDisc := 1
+	for _, image := range grouped[ArtworkDisc] {
+		disc := image.number
+		if disc == 0 {
+			for usedDiscs[nextDisc] {
+				nextDisc++
+			}
+			disc = nextDisc
+			usedDiscs[disc] = true
+		}
+		claim(image.name, fmt.Sprintf("disc%d", disc))
+	}
+
+	booklets := grouped[ArtworkBooklet]
+	for i, image := range booklets {
+		if len(booklets) == 1 {
+			claim(image.name, "booklet")
+		} else {
+			claim(image.name, fmt.Sprintf("booklet%02d", i+1))
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyArtwork(t *testing.T) {
+	tests := []struct {
+		filename   string
+		wantKind   ArtworkKind
+		wantNumber int
+	}{
+		{filename: "Back.jpg", wantKind: ArtworkBack},
+		{filename: "inlay.png", wantKind: ArtworkBack},
+		{filename: "cd1.jpg", wantKind: ArtworkDisc, wantNumber: 1},
+		{filename: "Disc 2.jpg", wantKind: ArtworkDisc, wantNumber: 2},
+		{filename: "booklet-03.jpg", wantKind: ArtworkBooklet, wantNumber: 3},
+		{filename: "notes.pdf", wantKind: ArtworkBooklet},
+		{filename: "artist.jpg", wantKind: ArtworkOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			gotKind, gotNumber := ClassifyArtwork("../test/flacs", tt.filename)
+			if gotKind != tt.wantKind || gotNumber != tt.wantNumber {
+				t.Errorf("ClassifyArtwork() got = %v %d, want %v %d", gotKind, gotNumber, tt.wantKind, tt.wantNumber)
+			}
+		})
+	}
+}
+
+func TestArtworkNames(t *testing.T) {
+	images := []string{"Rear.JPG", "back.jpg", "CD.jpg", "cd2.png", "scan 2.jpg", "scan 1.jpg", "artist.jpg"}
+	want := map[string]string{
+		"back.jpg":   "back.jpg",
+		"Rear.JPG":   "back2.jpg",
+		"CD.jpg":     "disc1.jpg",
+		"cd2.png":    "disc2.png",
+		"scan 1.jpg": "booklet01.jpg",
+		"scan 2.jpg": "booklet02.jpg",
+		"artist.jpg": "artist.jpg",
+	}
+
+	if got := ArtworkNames("../test/flacs", images); !reflect.DeepEqual(got, want) {
+		t.Errorf("ArtworkNames() got = %v, want %v", got, want)
+	}
+}
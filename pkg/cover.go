@@ -0,0 +1,206 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	coverNameWeight       = 0.4
+	coverSquarenessWeight = 0.25
+	coverResolutionWeight = 0.25
+	coverSizeWeight       = 0.1
+
+	// covers of this width and height get the full resolution score
+	coverFullResolution = 1000
+	// aspect ratios deviating more than this from 1:1 get no squareness score
+	coverMaxRatioDeviation = 0.3
+
+	// MinCoverConfidence is the score below which the best candidate is not trusted to be the cover
+	MinCoverConfidence = 0.5
+	// MinCoverScoreMargin is the minimal score difference between the best candidates to not consider them a tie
+	MinCoverScoreMargin = 0.1
+)
+
+var (
+	// names that definitely describe the front cover
+	frontCoverNames = []string{"front", "cover", "devant", "albumart", "album art", "artwork"}
+	// names that are used for covers but often for low resolution thumbnails as well
+	thumbnailCoverNames = []string{"folder", "thumb", "albumartsmall"}
+)
+
+// CoverCandidate is an image of a directory scored by how likely it is the front cover
+type CoverCandidate struct {
+	Name   string
+	Score  float64
+	Width  int
+	Height int
+	Size   int64
+}
+
+// ScoreCovers scores the images of a directory by their name, squareness, resolution and file size. The
+// returned candidates are ordered from the best to the worst score, which ranges from -0.4 to 1.
+func ScoreCovers(dirname string, images []string) []CoverCandidate {
+	candidates := make([]CoverCandidate, 0, len(images))
+	var maxSize int64
+	for _, image := range images {
+		candidate := CoverCandidate{Name: image}
+		path := filepath.Join(dirname, image)
+		if info, err := os.Stat(path); err == nil {
+			candidate.Size = info.Size()
+			maxSize = max(maxSize, candidate.Size)
+		}
+		if width, height, err := ImageDimensions(path); err == nil {
+			candidate.Width, candidate.Height = width, height
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		c.Score = coverNameWeight*coverNameScore(c.Name) +
+			coverSquarenessWeight*coverSquarenessScore(c.Width, c.Height) +
+			coverResolutionWeight*coverResolutionScore(c.Width, c.Height)
+		if maxSize > 0 {
+			c.Score += coverSizeWeight * float64(c.Size) / float64(maxSize)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates
+}
+
+// IsAmbiguousCoverChoice checks whether the best of the scored candidates is either not confident or
+// hardly better than the second best.
+func IsAmbiguousCoverChoice(candidates []CoverCandidate) bool {
+	if len(candidates) == 0 {
+		return false
+	}
+
+	if candidates[0].Score < MinCoverConfidence {
+		return true
+	}
+
+	return len(candidates) > 1 && candidates[0].Score-candidates[1].Score < MinCoverScoreMargin
+}
+
+// IsConfidentCover checks whether a scored candidate can be taken as the front cover without asking. Its
+// score has to reach MinCoverConfidence and its name must not describe other artwork such as the back.
+func IsConfidentCover(candidate CoverCandidate) bool {
+	return candidate.Score >= MinCoverConfidence && coverNameScore(candidate.Name) >= 0
+}
+
+// coverNameScore rates the basename of an image from -1 (other artwork) to 1 (front cover)
+func coverNameScore(filename string) float64 {
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+
+	hasName := func(names []string) bool {
+		return slices.ContainsFunc(names, func(name string) bool {
+			return base == name || strings.HasPrefix(base, name+"-") || strings.HasPrefix(base, name+"_")
+		})
+	}
+
+	switch {
+	case hasName(frontCoverNames):
+		return 1
+	case hasName(thumbnailCoverNames):
+		return 0.7
+	}
+
+	tokens := artworkTokenRegex.FindAllString(base, -1)
+	hasToken := func(names []string) bool {
+		return slices.ContainsFunc(tokens, func(token string) bool {
+			return slices.Contains(names, token)
+		})
+	}
+
+	switch {
+	case hasToken(backNames), hasToken(bookletNames), hasToken(discNames):
+		return -1
+	case hasToken(frontCoverNames):
+		return 0.5
+	}
+
+	return 0
+}
+
+// coverSquarenessScore rates the aspect ratio of an image from 0 to 1 (square)
+func coverSquarenessScore(width, height int) float64 {
+	if width == 0 || height == 0 {
+		return 0
+	}
+
+	deviation := math.Abs(float64(width)/float64(height) - 1)
+	return math.Max(0, 1-deviation/coverMaxRatioDeviation)
+}
+
+// coverResolutionScore rates the smaller side of an image from 0 to 1 (full resolution)
+func coverResolutionScore(width, height int) float64 {
+	return math.Min(1, float64(min(width, height))/coverFullResolution)
+}
+
+// Names of the cover quality issues
+const (
+	CoverTooSmall     = "cover-too-small"
+	CoverTooLarge     = "cover-too-large"
+	CoverNotSquare    = "cover-not-square"
+	CoverTooManyBytes = "cover-too-many-bytes"
+	CoverMIMEType     = "cover-mime-type"
+)
+
+// CoverRequirements describes the quality a cover has to meet. Zero values disable the respective check.
+type CoverRequirements struct {
+	// MinDimension is the minimal width and height in pixels
+	MinDimension int
+	// MaxDimension is the maximal width and height in pixels
+	MaxDimension int
+	// MaxBytes is the maximal size of the image data
+	MaxBytes int64
+	// MIMETypes lists the allowed MIME types
+	MIMETypes []string
+	// SquareTolerance is the allowed deviation of the aspect ratio from 1:1, see IsNearlySquare
+	SquareTolerance float64
+}
+
+// CoverIssue is a requirement a cover does not meet
+type CoverIssue struct {
+	Issue   string
+	Message string
+}
+
+// Check returns the requirements a cover with the given properties does not meet. Unknown dimensions
+// (zero) are not checked.
+func (r CoverRequirements) Check(width, height int, size int64, mimeType string) []CoverIssue {
+	var issues []CoverIssue
+	if width > 0 && height > 0 {
+		if r.MinDimension > 0 && min(width, height) < r.MinDimension {
+			issues = append(issues, CoverIssue{CoverTooSmall, fmt.Sprintf("%dx%d is smaller than %dx%d", width, height, r.MinDimension, r.MinDimension)})
+		}
+		if r.MaxDimension > 0 && max(width, height) > r.MaxDimension {
+			issues = append(issues, CoverIssue{CoverTooLarge, fmt.Sprintf("%dx%d is larger than %dx%d", width, height, r.MaxDimension, r.MaxDimension)})
+		}
+		if r.SquareTolerance > 0 && !IsNearlySquareDimensions(width, height, r.SquareTolerance) {
+			issues = append(issues, CoverIssue{CoverNotSquare, fmt.Sprintf("%dx%d is not square", width, height)})
+		}
+	}
+
+	if r.MaxBytes > 0 && size > r.MaxBytes {
+		issues = append(issues, CoverIssue{CoverTooManyBytes, fmt.Sprintf("%s exceeds %s", HumanSize(size), HumanSize(r.MaxBytes))})
+	}
+
+	if len(r.MIMETypes) > 0 && !slices.ContainsFunc(r.MIMETypes, func(allowed string) bool {
+		return strings.EqualFold(allowed, mimeType)
+	}) {
+		issues = append(issues, CoverIssue{CoverMIMEType, fmt.Sprintf("%q is not one of %v", mimeType, r.MIMETypes)})
+	}
+
+	return issues
+}
@@ -0,0 +1,168 @@
+package pkg
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func writeTestImage(t *testing.T, dir, name string, width, height int) {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScoreCovers(t *testing.T) {
+	tests := []struct {
+		name          string
+		images        map[string][2]int
+		want          string
+		wantAmbiguous bool
+	}{
+		{
+			name:   "high resolution front beats folder thumbnail",
+			images: map[string][2]int{"folder.png": {200, 200}, "front.png": {1400, 1400}},
+			want:   "front.png",
+		},
+		{
+			name:   "square image beats back by name",
+			images: map[string][2]int{"back.png": {1400, 1100}, "image.png": {1000, 1000}},
+			want:   "image.png",
+		},
+		{
+			name:   "folder thumbnail beats unnamed landscape image",
+			images: map[string][2]int{"folder.png": {500, 500}, "image.png": {800, 400}},
+			want:   "folder.png",
+		},
+		{
+			name:          "unnamed images are ambiguous",
+			images:        map[string][2]int{"a.png": {600, 600}, "b.png": {600, 600}},
+			want:          "a.png",
+			wantAmbiguous: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var images []string
+			for name, dimensions := range tt.images {
+				writeTestImage(t, dir, name, dimensions[0], dimensions[1])
+				images = append(images, name)
+			}
+			sort.Strings(images)
+
+			candidates := ScoreCovers(dir, images)
+			if len(candidates) != len(images) {
+				t.Fatalf("ScoreCovers() returned %d candidates, want %d", len(candidates), len(images))
+			}
+			if candidates[0].Name != tt.want {
+				t.Errorf("ScoreCovers() best = %q, want %q (%v)", candidates[0].Name, tt.want, candidates)
+			}
+			if got := IsAmbiguousCoverChoice(candidates); got != tt.wantAmbiguous {
+				t.Errorf("IsAmbiguousCoverChoice() = %v, want %v (%v)", got, tt.wantAmbiguous, candidates)
+			}
+		})
+	}
+}
+
+func TestIsConfidentCover(t *testing.T) {
+	tests := []struct {
+		candidate CoverCandidate
+		want      bool
+	}{
+		{candidate: CoverCandidate{Name: "front.jpg", Score: 0.9}, want: true},
+		{candidate: CoverCandidate{Name: "image.jpg", Score: MinCoverConfidence}, want: true},
+		{candidate: CoverCandidate{Name: "folder.jpg", Score: 0.4}, want: false},
+		{candidate: CoverCandidate{Name: "back.jpg", Score: 0.6}, want: false},
+	}
+	for _, tt := range tests {
+		if got := IsConfidentCover(tt.candidate); got != tt.want {
+			t.Errorf("IsConfidentCover(%+v) = %v, want %v", tt.candidate, got, tt.want)
+		}
+	}
+
+	dir := t.TempDir()
+	writeTestImage(t, dir, "back.png", 1400, 1400)
+	writeTestImage(t, dir, "booklet-01.png", 1400, 1400)
+	candidates := ScoreCovers(dir, []string{"back.png", "booklet-01.png"})
+	if IsConfidentCover(candidates[0]) {
+		t.Errorf("IsConfidentCover(%+v) = true for a folder without a front cover", candidates[0])
+	}
+}
+
+func TestCoverNameScore(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     float64
+	}{
+		{filename: "Front.jpg", want: 1},
+		{filename: "cover_large.png", want: 1},
+		{filename: "folder.jpg", want: 0.7},
+		{filename: "Album Cover HQ.jpg", want: 0.5},
+		{filename: "cd1.jpg", want: -1},
+		{filename: "artist.jpg", want: 0},
+	}
+	for _, tt := range tests {
+		if got := coverNameScore(tt.filename); got != tt.want {
+			t.Errorf("coverNameScore(%q) = %v, want %v", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestCoverRequirementsCheck(t *testing.T) {
+	requirements := CoverRequirements{
+		MinDimension:    500,
+		MaxDimension:    2000,
+		MaxBytes:        1_000_000,
+		MIMETypes:       []string{"image/jpeg", "image/png"},
+		SquareTolerance: 0.05,
+	}
+
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		size     int64
+		mimeType string
+		want     []string
+	}{
+		{name: "fine", width: 1000, height: 1000, size: 300_000, mimeType: "image/jpeg"},
+		{name: "mime case", width: 1000, height: 1000, size: 300_000, mimeType: "IMAGE/PNG"},
+		{name: "small", width: 300, height: 300, size: 30_000, mimeType: "image/jpeg", want: []string{CoverTooSmall}},
+		{name: "large and heavy", width: 3000, height: 3000, size: 3_000_000, mimeType: "image/jpeg", want: []string{CoverTooLarge, CoverTooManyBytes}},
+		{name: "not square", width: 1000, height: 800, size: 300_000, mimeType: "image/jpeg", want: []string{CoverNotSquare}},
+		{name: "mime", width: 1000, height: 1000, size: 300_000, mimeType: "image/webp", want: []string{CoverMIMEType}},
+		{name: "unknown dimensions", size: 300_000, mimeType: "image/jpeg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range requirements.Check(tt.width, tt.height, tt.size, tt.mimeType) {
+				got = append(got, issue.Issue)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverRequirementsCheckDisabled(t *testing.T) {
+	if issues := (CoverRequirements{}).Check(5000, 4000, 50_000_000, "image/webp"); len(issues) > 0 {
+		t.Errorf("Check() with disabled limits = %v, want no issues", issues)
+	}
+}
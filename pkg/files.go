@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"fmt"
 	"os/user"
 	"path/filepath"
 	"strings"
@@ -20,3 +21,23 @@ func GetExpandedFile(filename string) string {
 
 	return filename
 }
+
+// HumanSize formats a size in bytes using decimal units, e.g. "412MB" or "1.2GB".
+func HumanSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit && exp < 3; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	value := float64(size) / float64(div)
+	if value >= 100 {
+		return fmt.Sprintf("%.0f%cB", value, "kMGT"[exp])
+	}
+	return fmt.Sprintf("%.1f%cB", value, "kMGT"[exp])
+}
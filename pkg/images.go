@@ -4,16 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"math"
 	"os"
-	"path/filepath"
-	"strings"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
 )
 
-// IsValidImage checks if the file is a valid JPEG or PNG image,
-// and returns its validity, format (jpeg/png), MIME type, and error.
+var imageMIMETypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"bmp":  "image/bmp",
+	"tiff": "image/tiff",
+	"webp": "image/webp",
+}
+
+// IsValidImage checks if the file is a valid JPEG, PNG, GIF, BMP, TIFF or WebP image,
+// and returns its validity, format (e.g. jpeg), MIME type, and error.
 func IsValidImage(filePath string) (bool, string, string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -28,13 +40,8 @@ func IsValidImage(filePath string) (bool, string, string, error) {
 		return false, "", "", nil // Not a valid image
 	}
 
-	var mimeType string
-	switch format {
-	case "jpeg":
-		mimeType = "image/jpeg"
-	case "png":
-		mimeType = "image/png"
-	default:
+	mimeType, found := imageMIMETypes[format]
+	if !found {
 		return false, format, "", nil
 	}
 
@@ -43,7 +50,7 @@ func IsValidImage(filePath string) (bool, string, string, error) {
 
 var ErrNoImages = errors.New("no images found")
 
-// GetMainCover tries to guess which image is the correct cover
+// GetMainCover tries to guess which image is the correct cover, see ScoreCovers
 func GetMainCover(dirname string, images []string) (string, error) {
 	if len(images) == 0 {
 		return "", ErrNoImages
@@ -53,49 +60,25 @@ func GetMainCover(dirname string, images []string) (string, error) {
 		return images[0], nil
 	}
 
-	// Look for cover-indicating names
-	var coverNames = []string{
-		"front",
-		"folder",
-		"cover",
-		"devant",
-		"albumart",
-		"album art",
-		"artwork",
-	}
-
-	// Look for cover-indicating names (basename, ignoring extension)
-	for _, image := range images {
-		base := strings.ToLower(strings.TrimSuffix(filepath.Base(image), filepath.Ext(image)))
-		for _, name := range coverNames {
-			if base == name || strings.HasPrefix(base, name+"-") || strings.HasPrefix(base, name+"_") {
-				return image, nil
-			}
-		}
-	}
+	return ScoreCovers(dirname, images)[0].Name, nil
+}
 
-	// Look for a square (or near-square) image, typical for album art
-	for _, image := range images {
-		path := filepath.Join(dirname, image)
-		if IsNearlySquare(path, 0.1) {
-			return image, nil
-		}
+// ImageDimensions returns the width and height of the image at path.
+func ImageDimensions(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
 	}
+	defer func() {
+		_ = f.Close()
+	}()
 
-	// Fall back to the largest file
-	maxSize := int64(0)
-	maxImage := images[0]
-	for _, image := range images {
-		path := filepath.Join(dirname, image)
-		if info, err := os.Stat(path); err == nil {
-			if info.Size() > maxSize {
-				maxSize = info.Size()
-				maxImage = image
-			}
-		}
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return maxImage, nil
+	return cfg.Width, cfg.Height, nil
 }
 
 // IsNearlySquare returns true if the image at path has an aspect ratio
@@ -114,10 +97,15 @@ func IsNearlySquare(path string, tolerance float64) bool {
 		return false
 	}
 
-	if cfg.Height == 0 {
+	return IsNearlySquareDimensions(cfg.Width, cfg.Height, tolerance)
+}
+
+// IsNearlySquareDimensions is IsNearlySquare for an image of known dimensions.
+func IsNearlySquareDimensions(width, height int, tolerance float64) bool {
+	if height == 0 {
 		return false
 	}
 
-	ratio := float64(cfg.Width) / float64(cfg.Height)
+	ratio := float64(width) / float64(height)
 	return math.Abs(ratio-1.0) <= tolerance
 }
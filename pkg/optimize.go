@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	DefaultMaxDimension = 1000
+	DefaultJpegQuality  = 90
+)
+
+// OptimizeOpts configures how an image is prepared for embedding.
+type OptimizeOpts struct {
+	// MaxDimension is the maximum width and height of the result, 0 keeps the original size.
+	MaxDimension int
+	// Quality is the JPEG quality of the result, ranging from 1 to 100.
+	Quality int
+}
+
+// OptimizeImage decodes the image at path, scales it down so it fits into the max dimension and encodes
+// it as a baseline JPEG. Re-encoding drops all EXIF and ICC data, transparent areas become white.
+func OptimizeImage(path string, opts OptimizeOpts) ([]byte, error) {
+	if opts.Quality < 1 || opts.Quality > 100 {
+		return nil, fmt.Errorf("jpeg quality must be between 1 and 100, got %d", opts.Quality)
+	}
+
+	if opts.MaxDimension < 0 {
+		return nil, errors.New("max dimension must not be negative")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %q: %w", path, err)
+	}
+
+	bounds := src.Bounds()
+	width, height := scaledDimensions(bounds.Dx(), bounds.Dy(), opts.MaxDimension)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	// the standard library only writes baseline JPEGs without any metadata segments
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: opts.Quality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteOptimizedImage optimizes the image at src and writes the result to a new temporary file in dir.
+// The caller is responsible for removing the returned file.
+func WriteOptimizedImage(src, dir string, opts OptimizeOpts) (string, error) {
+	data, err := OptimizeImage(src, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return writeTempImage(dir, ".jpg", data)
+}
+
+// IsEmbeddableFormat checks whether pictures of the given format (as returned by IsValidImage) can be
+// embedded as they are. Most FLAC players only display JPEG and PNG pictures.
+func IsEmbeddableFormat(format string) bool {
+	return format == "jpeg" || format == "png"
+}
+
+// ConvertImage decodes the image at path and encodes it as PNG if it contains transparency and as a
+// baseline JPEG otherwise. It returns the encoded image and the matching file extension. Animated
+// images are reduced to their first frame.
+func ConvertImage(path string) ([]byte, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %q: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	if opaque, ok := src.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: DefaultJpegQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), ".jpg", nil
+	}
+
+	if err := png.Encode(&buf, src); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), ".png", nil
+}
+
+// WriteEmbeddableImage returns src if it is a JPEG or PNG image. Images of other formats are converted
+// using ConvertImage and written to a new temporary file in dir. The returned bool is true if a temporary
+// file has been written, the caller is responsible for removing it.
+func WriteEmbeddableImage(src, dir string) (string, bool, error) {
+	isValid, format, _, err := IsValidImage(src)
+	if err != nil {
+		return "", false, err
+	}
+	if !isValid {
+		return "", false, fmt.Errorf("%q is not a supported image", src)
+	}
+
+	if IsEmbeddableFormat(format) {
+		return src, false, nil
+	}
+
+	data, ext, err := ConvertImage(src)
+	if err != nil {
+		return "", false, err
+	}
+
+	converted, err := writeTempImage(dir, ext, data)
+	if err != nil {
+		return "", false, err
+	}
+	return converted, true, nil
+}
+
+func writeTempImage(dir, ext string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(dir, "flac-mate-*"+ext)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+// EstimateOptimizedSize estimates the size of an image of the given size and dimensions after it has been
+// scaled down to maxDimension. The estimate assumes the size grows linearly with the number of pixels.
+func EstimateOptimizedSize(size int64, width, height, maxDimension int) int64 {
+	if width <= 0 || height <= 0 {
+		return size
+	}
+
+	scaledWidth, scaledHeight := scaledDimensions(width, height, maxDimension)
+	return size * int64(scaledWidth*scaledHeight) / int64(width*height)
+}
+
+// scaledDimensions returns the dimensions that fit into maxDimension while keeping the aspect ratio.
+// Images are never scaled up.
+func scaledDimensions(width, height, maxDimension int) (int, int) {
+	if maxDimension == 0 || (width <= maxDimension && height <= maxDimension) {
+		return width, height
+	}
+
+	if width >= height {
+		return maxDimension, max(1, height*maxDimension/width)
+	}
+	return max(1, width*maxDimension/height), maxDimension
+}
@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+func TestOptimizeImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3000, 1500))
+	for x := 0; x < 3000; x++ {
+		src.Set(x, x%1500, color.NRGBA{R: 200, A: 128})
+	}
+
+	path := filepath.Join(t.TempDir(), "cover.png")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := OptimizeImage(path, OptimizeOpts{MaxDimension: 1000, Quality: 85})
+	if err != nil {
+		t.Fatalf("OptimizeImage() error = %v", err)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(got))
+	if err != nil {
+		t.Fatalf("could not decode result: %v", err)
+	}
+	if format != "jpeg" || cfg.Width != 1000 || cfg.Height != 500 {
+		t.Errorf("OptimizeImage() got = %s %dx%d, want jpeg 1000x500", format, cfg.Width, cfg.Height)
+	}
+
+	// baseline JPEGs use the SOF0 marker, progressive ones SOF2
+	if !bytes.Contains(got, []byte{0xff, 0xc0}) || bytes.Contains(got, []byte{0xff, 0xc2}) {
+		t.Errorf("OptimizeImage() did not produce a baseline jpeg")
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(got)); err != nil {
+		t.Errorf("could not decode result: %v", err)
+	}
+}
+
+func TestScaledDimensions(t *testing.T) {
+	tests := []struct {
+		width, height, maxDimension int
+		wantWidth, wantHeight       int
+	}{
+		{width: 3000, height: 3000, maxDimension: 1000, wantWidth: 1000, wantHeight: 1000},
+		{width: 1200, height: 1600, maxDimension: 800, wantWidth: 600, wantHeight: 800},
+		{width: 500, height: 500, maxDimension: 1000, wantWidth: 500, wantHeight: 500},
+		{width: 500, height: 400, maxDimension: 0, wantWidth: 500, wantHeight: 400},
+	}
+	for _, tt := range tests {
+		gotWidth, gotHeight := scaledDimensions(tt.width, tt.height, tt.maxDimension)
+		if gotWidth != tt.wantWidth || gotHeight != tt.wantHeight {
+			t.Errorf("scaledDimensions(%d, %d, %d) got = %dx%d, want %dx%d", tt.width, tt.height, tt.maxDimension, gotWidth, gotHeight, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestEstimateOptimizedSize(t *testing.T) {
+	tests := []struct {
+		size                        int64
+		width, height, maxDimension int
+		want                        int64
+	}{
+		{size: 4_000_000, width: 2000, height: 2000, maxDimension: 1000, want: 1_000_000},
+		{size: 300_000, width: 800, height: 800, maxDimension: 1000, want: 300_000},
+		{size: 300_000, width: 0, height: 0, maxDimension: 1000, want: 300_000},
+		{size: 900_000, width: 3000, height: 1500, maxDimension: 1000, want: 100_000},
+	}
+	for _, tt := range tests {
+		if got := EstimateOptimizedSize(tt.size, tt.width, tt.height, tt.maxDimension); got != tt.want {
+			t.Errorf("EstimateOptimizedSize(%d, %d, %d, %d) = %d, want %d", tt.size, tt.width, tt.height, tt.maxDimension, got, tt.want)
+		}
+	}
+}
+
+func TestWriteEmbeddableImage(t *testing.T) {
+	dir := t.TempDir()
+
+	opaque := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	fill := func(img *image.RGBA, c color.Color) {
+		for x := 0; x < 40; x++ {
+			for y := 0; y < 30; y++ {
+				img.Set(x, y, c)
+			}
+		}
+	}
+	fill(opaque, color.RGBA{R: 255, A: 255})
+
+	transparent := image.NewPaletted(image.Rect(0, 0, 40, 30), color.Palette{color.Transparent, color.Black})
+
+	write := func(name string, encode func(f *os.File) error) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		if err := encode(f); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		wantConverted bool
+		wantFormat    string
+	}{
+		{
+			name:       "png is kept",
+			path:       write("cover.png", func(f *os.File) error { return png.Encode(f, opaque) }),
+			wantFormat: "png",
+		},
+		{
+			name:          "opaque bmp becomes jpeg",
+			path:          write("cover.bmp", func(f *os.File) error { return bmp.Encode(f, opaque) }),
+			wantConverted: true,
+			wantFormat:    "jpeg",
+		},
+		{
+			name:          "transparent gif becomes png",
+			path:          write("cover.gif", func(f *os.File) error { return gif.Encode(f, transparent, nil) }),
+			wantConverted: true,
+			wantFormat:    "png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, converted, err := WriteEmbeddableImage(tt.path, dir)
+			if err != nil {
+				t.Fatalf("WriteEmbeddableImage() error = %v", err)
+			}
+			if converted {
+				defer func() {
+					_ = os.Remove(got)
+				}()
+			}
+
+			if converted != tt.wantConverted {
+				t.Errorf("WriteEmbeddableImage() converted = %v, want %v", converted, tt.wantConverted)
+			}
+			if !converted && got != tt.path {
+				t.Errorf("WriteEmbeddableImage() got = %q, want %q", got, tt.path)
+			}
+
+			isValid, format, _, err := IsValidImage(got)
+			if err != nil || !isValid || format != tt.wantFormat {
+				t.Errorf("WriteEmbeddableImage() format = %q, want %q", format, tt.wantFormat)
+			}
+
+			width, height, err := ImageDimensions(got)
+			if err != nil || width != 40 || height != 30 {
+				t.Errorf("ImageDimensions() = %dx%d, want 40x30", width, height)
+			}
+		})
+	}
+}
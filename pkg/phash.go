@@ -0,0 +1,44 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math/bits"
+
+	"golang.org/x/image/draw"
+)
+
+// DifferenceHash computes a 64 bit perceptual hash of an image. The image is reduced to 9x8 grayscale
+// pixels and every bit tells whether a pixel is brighter than its right neighbour, so the hash survives
+// scaling, recompression and slight color changes.
+func DifferenceHash(img image.Image) uint64 {
+	gray := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.BiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if gray.GrayAt(x, y).Y > gray.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// DifferenceHashData decodes the image data and computes its DifferenceHash.
+func DifferenceHashData(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("could not decode image: %w", err)
+	}
+	return DifferenceHash(img), nil
+}
+
+// HammingDistance returns the number of differing bits of two perceptual hashes, ranging from 0
+// (identical looking) to 64.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+func gradient(width, height int, inverted bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			v := uint8(x*160/width + y*90/height)
+			if (x/(width/4))%2 == 1 {
+				v = 255 - v
+			}
+			if inverted {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestDifferenceHash(t *testing.T) {
+	original := gradient(400, 400, false)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, 150, 150))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), original, original.Bounds(), draw.Src, nil)
+
+	tests := []struct {
+		name        string
+		img         image.Image
+		maxDistance int
+		minDistance int
+	}{
+		{name: "identical", img: original, maxDistance: 0},
+		{name: "scaled down", img: scaled, maxDistance: 4},
+		{name: "inverted", img: gradient(400, 400, true), minDistance: 20, maxDistance: 64},
+	}
+
+	want := DifferenceHash(original)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := HammingDistance(want, DifferenceHash(tt.img))
+			if distance < tt.minDistance || distance > tt.maxDistance {
+				t.Errorf("HammingDistance() = %d, want between %d and %d", distance, tt.minDistance, tt.maxDistance)
+			}
+		})
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{a: 0, b: 0, want: 0},
+		{a: 0b1011, b: 0b0001, want: 2},
+		{a: ^uint64(0), b: 0, want: 64},
+	}
+	for _, tt := range tests {
+		if got := HammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HammingDistance(%b, %b) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}